@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsServer serves the metrics of a benchmark run in the Prometheus
+// exposition format on /metrics. Its registry starts with the Go runtime and
+// process collectors; the runner adds the repository and connection pool ones.
+type MetricsServer struct {
+	registry *prometheus.Registry
+	listener net.Listener
+	server   *http.Server
+	served   chan error
+}
+
+// NewMetricsServer starts serving on addr, such as 127.0.0.1:9100.
+func NewMetricsServer(addr string) (*MetricsServer, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+	s := &MetricsServer{
+		registry: registry,
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		served:   make(chan error, 1),
+	}
+	go func() {
+		s.served <- s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// Registry returns the registry whose collectors are served.
+func (s *MetricsServer) Registry() *prometheus.Registry {
+	return s.registry
+}
+
+// URL returns the address metrics are served on.
+func (s *MetricsServer) URL() string {
+	return "http://" + s.listener.Addr().String() + "/metrics"
+}
+
+// Close stops the server, letting scrapes in progress finish.
+func (s *MetricsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-s.served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/config"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/migrations"
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+const migrateUsage = "usage: migrate up | down | to <version> | status"
+
+// runMigrateCommand applies a migration command to both benchmark databases
+// so their schemas stay identical, logging the resulting status to logger.
+func runMigrateCommand(cfg config.Config, args []string, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	for _, database := range commandDatabases(cfg) {
+		db, err := sql.Open(database.driverName, database.config.ConnString())
+		if err != nil {
+			return fmt.Errorf("[%s] failed to connect: %w", database.name, err)
+		}
+		err = runMigration(context.Background(), logger, db, database.name, database.config.Schema, args)
+		db.Close()
+		if err != nil {
+			return fmt.Errorf("[%s] %w", database.name, err)
+		}
+	}
+	return nil
+}
+
+// commandDatabase is a benchmark database opened without instrumentation by
+// the maintenance commands.
+type commandDatabase struct {
+	name       string
+	driverName string
+	config     config.DatabaseConfig
+}
+
+// commandDatabases lists the SQLC and GORM databases under the configured
+// isolation mode, with the driver each library uses.
+func commandDatabases(cfg config.Config) []commandDatabase {
+	sqlcConfig, gormConfig := cfg.RepositoryDatabases()
+	return []commandDatabase{
+		{"SQLC", "postgres", sqlcConfig},
+		{"GORM", "pgx", gormConfig},
+	}
+}
+
+func runMigration(ctx context.Context, logger *slog.Logger, db *sql.DB, dbName, schema string, args []string) error {
+	if schema != "" {
+		if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(schema)); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], parseErr)
+		}
+		err = migrator.To(ctx, version)
+	case "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	dbLogger := logger.With(pkgs.Repository(dbName))
+	dbLogger.Info("Migration status", "version", status.Current, "dirty", status.Dirty)
+	for _, migration := range status.Applied {
+		dbLogger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	for _, migration := range status.Pending {
+		dbLogger.Info("Pending migration", "version", migration.Version, "name", migration.Name)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PgStatCollector reads server-side statement statistics from the
+// pg_stat_statements extension. Statistics are scoped to the database the
+// collector is connected to, so the SQLC and GORM databases can share a server.
+type PgStatCollector struct {
+	db *sql.DB
+}
+
+// PgStatSnapshot is the server-side work recorded for one benchmark phase.
+type PgStatSnapshot struct {
+	Calls    int64
+	Rows     int64
+	ExecTime time.Duration
+}
+
+// NewPgStatCollector makes sure pg_stat_statements is installed and loaded.
+// The extension has to be listed in shared_preload_libraries on the server.
+func NewPgStatCollector(ctx context.Context, db *sql.DB) (*PgStatCollector, error) {
+	if _, err := db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS pg_stat_statements"); err != nil {
+		return nil, fmt.Errorf("failed to create pg_stat_statements extension: %w", err)
+	}
+	c := &PgStatCollector{db: db}
+	if _, err := c.Read(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reset clears the statistics of the current database.
+func (c *PgStatCollector) Reset(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, `SELECT pg_stat_statements_reset(0, (SELECT oid FROM pg_database WHERE datname = current_database()), 0)`)
+	if err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	return nil
+}
+
+// Read sums the statistics recorded for the current database since the last
+// reset, leaving out the collector's own statements.
+func (c *PgStatCollector) Read(ctx context.Context) (PgStatSnapshot, error) {
+	var snapshot PgStatSnapshot
+	var execTimeMs float64
+	err := c.db.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(calls), 0), COALESCE(SUM(rows), 0), COALESCE(SUM(total_exec_time), 0)
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+  AND query NOT LIKE '%pg_stat_statements%'`).Scan(&snapshot.Calls, &snapshot.Rows, &execTimeMs)
+	if err != nil {
+		return PgStatSnapshot{}, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	snapshot.ExecTime = time.Duration(execTimeMs * float64(time.Millisecond))
+	return snapshot, nil
+}
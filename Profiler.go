@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// Profiler captures a CPU profile, a heap profile and an execution trace for
+// every benchmark phase. Files are written to a per-run directory and named
+// <repository>_<operation>.<kind> so that SQLC and GORM profiles of the same
+// operation sit next to each other and can be compared with `go tool pprof -diff_base`.
+type Profiler struct {
+	dir    string
+	logger *slog.Logger
+}
+
+// NewProfiler creates a timestamped run directory below baseDir. Failures to
+// write a heap profile are logged to logger.
+func NewProfiler(baseDir string, logger *slog.Logger) (*Profiler, error) {
+	dir := filepath.Join(baseDir, time.Now().Format("20060102_150405"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return &Profiler{dir: dir, logger: logger}, nil
+}
+
+// Dir returns the run directory the profiles are written to.
+func (p *Profiler) Dir() string {
+	return p.dir
+}
+
+// Start begins CPU profiling and tracing for a benchmark phase. The returned
+// function stops both and writes the heap profile. A nil Profiler is a no-op.
+func (p *Profiler) Start(repoName, operation string) (stop func(), err error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	prefix := filepath.Join(p.dir, strings.ToLower(repoName)+"_"+operation)
+
+	cpuFile, err := os.Create(prefix + ".cpu.pprof")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	traceFile, err := os.Create(prefix + ".trace.out")
+	if err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+	if err := trace.Start(traceFile); err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		traceFile.Close()
+		return nil, fmt.Errorf("failed to start trace: %w", err)
+	}
+
+	return func() {
+		trace.Stop()
+		traceFile.Close()
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+
+		if err := writeHeapProfile(prefix + ".heap.pprof"); err != nil {
+			p.logger.Warn("Failed to write heap profile", pkgs.Repository(repoName), pkgs.Operation(operation), "error", err)
+		}
+	}, nil
+}
+
+// writeHeapProfile writes an up-to-date heap profile to path.
+func writeHeapProfile(path string) error {
+	heapFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer heapFile.Close()
+
+	// Run a GC so the profile reflects the allocations of the phase that just finished
+	runtime.GC()
+	return pprof.WriteHeapProfile(heapFile)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/config"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/schemadiff"
+)
+
+// runSchemaDiffCommand compares the schemas of both benchmark databases.
+func runSchemaDiffCommand(cfg config.Config, logger *slog.Logger) error {
+	databases := commandDatabases(cfg)
+	var dbs []*sql.DB
+	for _, database := range databases {
+		db, err := sql.Open(database.driverName, database.config.ConnString())
+		if err != nil {
+			return fmt.Errorf("[%s] failed to connect: %w", database.name, err)
+		}
+		defer db.Close()
+		dbs = append(dbs, db)
+	}
+	return checkSchemas(context.Background(), logger,
+		dbs[0], databases[0].config.SchemaName(),
+		dbs[1], databases[1].config.SchemaName())
+}
+
+// checkSchemas logs every column, index and constraint difference between
+// the SQLC and GORM schemas to logger and fails if there is any.
+func checkSchemas(ctx context.Context, logger *slog.Logger, sqlcDB *sql.DB, sqlcSchemaName string, gormDB *sql.DB, gormSchemaName string) error {
+	sqlcSchema, err := schemadiff.Inspect(ctx, sqlcDB, sqlcSchemaName)
+	if err != nil {
+		return fmt.Errorf("[SQLC] failed to inspect schema: %w", err)
+	}
+	gormSchema, err := schemadiff.Inspect(ctx, gormDB, gormSchemaName)
+	if err != nil {
+		return fmt.Errorf("[GORM] failed to inspect schema: %w", err)
+	}
+
+	diffs := schemadiff.Compare(sqlcSchema, gormSchema)
+	if len(diffs) == 0 {
+		logger.Info("Schemas of the SQLC and GORM databases are equivalent")
+		return nil
+	}
+	for _, diff := range diffs {
+		logger.Error("Schema difference (SQLC vs GORM)", "difference", diff.String())
+	}
+	return fmt.Errorf("schemas of the SQLC and GORM databases differ in %d places", len(diffs))
+}
@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is the key-value store behind repositories.CachingRepository.
+// Values are opaque bytes so that external caches such as Redis or memcached
+// can be plugged in by implementing it; LRUCache keeps them in memory.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for key. A positive ttl expires the entry after that
+	// long; zero keeps it until it is evicted or deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the entries of keys, ignoring keys that are not stored.
+	Delete(ctx context.Context, keys ...string) error
+}
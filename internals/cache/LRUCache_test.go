@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// assertCached checks whether c holds key, and with which value.
+func assertCached(t *testing.T, c *LRUCache, key string, want bool, wantValue string) {
+	t.Helper()
+	value, ok, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) failed: %v", key, err)
+	}
+	if ok != want || (ok && string(value) != wantValue) {
+		t.Errorf("Get(%q) = %q, %t, want %q, %t", key, value, ok, wantValue, want)
+	}
+}
+
+func set(t *testing.T, c *LRUCache, key, value string, ttl time.Duration) {
+	t.Helper()
+	if err := c.Set(context.Background(), key, []byte(value), ttl); err != nil {
+		t.Fatalf("Set(%q) failed: %v", key, err)
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+	set(t, c, "a", "1", 0)
+	set(t, c, "b", "2", 0)
+	assertCached(t, c, "a", true, "1") // b is now the least recently used
+	set(t, c, "c", "3", 0)
+
+	assertCached(t, c, "b", false, "")
+	assertCached(t, c, "a", true, "1")
+	assertCached(t, c, "c", true, "3")
+
+	// Overwriting an entry uses it too
+	set(t, c, "a", "4", 0)
+	set(t, c, "d", "5", 0)
+	assertCached(t, c, "c", false, "")
+	assertCached(t, c, "a", true, "4")
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len = %d, want 2", n)
+	}
+}
+
+func TestLRUCacheExpiresEntries(t *testing.T) {
+	c := NewLRUCache(10)
+	set(t, c, "short", "1", 10*time.Millisecond)
+	set(t, c, "long", "2", time.Hour)
+	set(t, c, "forever", "3", 0)
+	assertCached(t, c, "short", true, "1")
+
+	time.Sleep(20 * time.Millisecond)
+	assertCached(t, c, "short", false, "")
+	assertCached(t, c, "long", true, "2")
+	assertCached(t, c, "forever", true, "3")
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len = %d, want the expired entry dropped", n)
+	}
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	c := NewLRUCache(10)
+	set(t, c, "a", "1", 0)
+	set(t, c, "b", "2", 0)
+	if err := c.Delete(context.Background(), "a", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	assertCached(t, c, "a", false, "")
+	assertCached(t, c, "b", true, "2")
+}
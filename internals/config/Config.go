@@ -0,0 +1,471 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile names the environment variable holding the path of the
+// optional YAML configuration file.
+const EnvConfigFile = "SQLCVSGORM_CONFIG"
+
+// Isolation modes, selecting how the SQLC and GORM data are kept apart.
+const (
+	// IsolationDatabases runs each library against its own database.
+	IsolationDatabases = "databases"
+	// IsolationSchemas runs both libraries against the SQLC database, each
+	// in its own schema.
+	IsolationSchemas = "schemas"
+	// IsolationShared runs both libraries against the same table in the
+	// SQLC database, which is truncated between benchmark phases.
+	IsolationShared = "shared"
+)
+
+// Default schema names used by IsolationSchemas.
+const (
+	DefaultSQLCSchema = "sqlc"
+	DefaultGORMSchema = "gorm"
+)
+
+// Config holds the settings of a benchmark run.
+type Config struct {
+	SQLC      DatabaseConfig  `yaml:"sqlc"`
+	GORM      DatabaseConfig  `yaml:"gorm"`
+	Isolation string          `yaml:"isolation"`
+	Log       LogConfig       `yaml:"log"`
+	Benchmark BenchmarkConfig `yaml:"benchmark"`
+	Faults    FaultsConfig    `yaml:"faults"`
+}
+
+// DatabaseConfig holds the connection string and pool settings of one database.
+// Zero pool values keep the database/sql defaults.
+type DatabaseConfig struct {
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// Schema is the PostgreSQL schema the tables live in; empty uses the
+	// server's default search_path.
+	Schema string `yaml:"schema"`
+}
+
+// SchemaName returns the schema the tables live in.
+func (d DatabaseConfig) SchemaName() string {
+	if d.Schema == "" {
+		return "public"
+	}
+	return d.Schema
+}
+
+// ConnString returns the DSN, selecting Schema through the search_path
+// run-time parameter when one is set.
+func (d DatabaseConfig) ConnString() string {
+	if d.Schema == "" {
+		return d.DSN
+	}
+	u, err := url.Parse(d.DSN)
+	if err != nil {
+		return d.DSN
+	}
+	query := u.Query()
+	query.Set("search_path", d.Schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// LogConfig holds where log files are written and how.
+type LogConfig struct {
+	Dir      string `yaml:"dir"`
+	FileName string `yaml:"file_name"`
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level"`
+	// Format is text or json.
+	Format string `yaml:"format"`
+	// MaxSizeMB and MaxAge rotate the log file once it grows past the size
+	// or gets older than the age; zero disables the rule.
+	MaxSizeMB int           `yaml:"max_size_mb"`
+	MaxAge    time.Duration `yaml:"max_age"`
+	// Compress gzips rotated files.
+	Compress bool `yaml:"compress"`
+	// MaxFiles and MaxDays bound how many rotated files are kept and for how
+	// long; zero keeps them all.
+	MaxFiles int `yaml:"max_files"`
+	MaxDays  int `yaml:"max_days"`
+	// SlowQuery is the duration from which repository calls logged with
+	// -log-calls are reported as slow; zero disables it. SlowQueries
+	// overrides it per method.
+	SlowQuery   time.Duration            `yaml:"slow_query"`
+	SlowQueries map[string]time.Duration `yaml:"slow_queries"`
+}
+
+// SlogLevel returns Level as a slog.Level, defaulting to info.
+func (l LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// BenchmarkConfig holds the parameters of the benchmark runner.
+type BenchmarkConfig struct {
+	// Count is the number of authors created per repository.
+	Count int `yaml:"count"`
+	// Seed seeds the random author generator; zero uses the current time.
+	Seed int64 `yaml:"seed"`
+	// BirthdateRangeYears is how many years back GetAuthorsByBirthdateRange looks.
+	BirthdateRangeYears int `yaml:"birthdate_range_years"`
+}
+
+// FaultsConfig holds the faults the runner injects into both repositories
+// when -faults is set.
+type FaultsConfig struct {
+	// Seed seeds the fault injection; zero uses the current time. Both
+	// repositories get the same seed and the same fault probabilities, but
+	// not necessarily the same faults on the same calls.
+	Seed uint64 `yaml:"seed"`
+	// Methods maps repository method names, or "*" for every method not
+	// listed, to the faults injected into their calls.
+	Methods map[string]FaultConfig `yaml:"methods"`
+}
+
+// FaultConfig holds the faults injected into one method. Rates are the
+// probabilities, between 0 and 1, that a call is hit by each fault.
+type FaultConfig struct {
+	ErrorRate float64 `yaml:"error_rate"`
+	// Error is the kind of error injected: connection, serialization,
+	// constraint, query, not_found, timeout or canceled. It defaults to
+	// connection.
+	Error       string        `yaml:"error"`
+	LatencyRate float64       `yaml:"latency_rate"`
+	Latency     time.Duration `yaml:"latency"`
+	// TimeoutRate calls hang for Timeout, 1s by default, and then fail.
+	TimeoutRate float64       `yaml:"timeout_rate"`
+	Timeout     time.Duration `yaml:"timeout"`
+	// PartialRate calls returning a list return only part of it.
+	PartialRate float64 `yaml:"partial_rate"`
+}
+
+// Default returns the default configuration. It holds no DSNs, which must be
+// set in the YAML file or the environment so that no credentials are built in.
+func Default() Config {
+	return Config{
+		Isolation: IsolationDatabases,
+		Log: LogConfig{
+			Dir:       "logs",
+			FileName:  "SqlcVsGorm.log",
+			Level:     "info",
+			Format:    "text",
+			MaxSizeMB: 100,
+			MaxFiles:  10,
+			MaxDays:   30,
+			SlowQuery: 100 * time.Millisecond,
+		},
+		Benchmark: BenchmarkConfig{
+			Count:               100,
+			BirthdateRangeYears: 5,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path and
+// finally the environment, each overriding the previous one. When path is
+// empty the file named by SQLCVSGORM_CONFIG is used, if any.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides settings with the SQLCVSGORM_* environment variables.
+func (c *Config) applyEnv() error {
+	var errs []error
+	databases := []struct {
+		prefix string
+		db     *DatabaseConfig
+	}{
+		{"SQLCVSGORM_SQLC_", &c.SQLC},
+		{"SQLCVSGORM_GORM_", &c.GORM},
+	}
+	for _, d := range databases {
+		prefix, db := d.prefix, d.db
+		envString(prefix+"DSN", &db.DSN)
+		errs = append(errs,
+			envInt(prefix+"MAX_OPEN_CONNS", &db.MaxOpenConns),
+			envInt(prefix+"MAX_IDLE_CONNS", &db.MaxIdleConns),
+			envDuration(prefix+"CONN_MAX_LIFETIME", &db.ConnMaxLifetime),
+			envDuration(prefix+"CONN_MAX_IDLE_TIME", &db.ConnMaxIdleTime),
+		)
+		envString(prefix+"SCHEMA", &db.Schema)
+	}
+	envString("SQLCVSGORM_ISOLATION", &c.Isolation)
+	envString("SQLCVSGORM_LOG_DIR", &c.Log.Dir)
+	envString("SQLCVSGORM_LOG_FILE_NAME", &c.Log.FileName)
+	envString("SQLCVSGORM_LOG_LEVEL", &c.Log.Level)
+	envString("SQLCVSGORM_LOG_FORMAT", &c.Log.Format)
+	errs = append(errs,
+		envInt("SQLCVSGORM_LOG_MAX_SIZE_MB", &c.Log.MaxSizeMB),
+		envDuration("SQLCVSGORM_LOG_MAX_AGE", &c.Log.MaxAge),
+		envBool("SQLCVSGORM_LOG_COMPRESS", &c.Log.Compress),
+		envInt("SQLCVSGORM_LOG_MAX_FILES", &c.Log.MaxFiles),
+		envInt("SQLCVSGORM_LOG_MAX_DAYS", &c.Log.MaxDays),
+		envDuration("SQLCVSGORM_LOG_SLOW_QUERY", &c.Log.SlowQuery),
+	)
+	errs = append(errs,
+		envInt("SQLCVSGORM_BENCHMARK_COUNT", &c.Benchmark.Count),
+		envInt64("SQLCVSGORM_BENCHMARK_SEED", &c.Benchmark.Seed),
+		envInt("SQLCVSGORM_BENCHMARK_BIRTHDATE_RANGE_YEARS", &c.Benchmark.BirthdateRangeYears),
+		envUint64("SQLCVSGORM_FAULTS_SEED", &c.Faults.Seed),
+	)
+	return errors.Join(errs...)
+}
+
+func envString(name string, dst *string) {
+	if value, ok := os.LookupEnv(name); ok {
+		*dst = value
+	}
+}
+
+func envInt(name string, dst *int) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envInt64(name string, dst *int64) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envUint64(name string, dst *uint64) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envBool(name string, dst *bool) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = b
+	return nil
+}
+
+func envDuration(name string, dst *time.Duration) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = d
+	return nil
+}
+
+// Validate reports every invalid setting.
+func (c Config) Validate() error {
+	var errs []error
+	// The single-database modes connect GORM to the SQLC database
+	errs = append(errs, c.SQLC.validate("sqlc", true), c.GORM.validate("gorm", c.Isolation == IsolationDatabases))
+	switch c.Isolation {
+	case IsolationDatabases, IsolationShared:
+	case IsolationSchemas:
+		sqlc, gorm := c.RepositoryDatabases()
+		if sqlc.Schema == gorm.Schema {
+			errs = append(errs, fmt.Errorf("sqlc.schema and gorm.schema must differ in %q isolation", IsolationSchemas))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("isolation must be %q, %q or %q, got %q", IsolationDatabases, IsolationSchemas, IsolationShared, c.Isolation))
+	}
+	if c.Log.Dir == "" {
+		errs = append(errs, errors.New("log.dir must not be empty"))
+	}
+	if c.Log.FileName == "" {
+		errs = append(errs, errors.New("log.file_name must not be empty"))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		errs = append(errs, fmt.Errorf("log.format must be text or json, got %q", c.Log.Format))
+	}
+	if c.Log.MaxSizeMB < 0 || c.Log.MaxAge < 0 || c.Log.MaxFiles < 0 || c.Log.MaxDays < 0 {
+		errs = append(errs, errors.New("log rotation and retention settings must not be negative"))
+	}
+	if c.Log.SlowQuery < 0 {
+		errs = append(errs, fmt.Errorf("log.slow_query must not be negative, got %s", c.Log.SlowQuery))
+	}
+	for method, threshold := range c.Log.SlowQueries {
+		if threshold < 0 {
+			errs = append(errs, fmt.Errorf("log.slow_queries.%s must not be negative, got %s", method, threshold))
+		}
+	}
+	if c.Benchmark.Count <= 0 {
+		errs = append(errs, fmt.Errorf("benchmark.count must be positive, got %d", c.Benchmark.Count))
+	}
+	if c.Benchmark.BirthdateRangeYears <= 0 {
+		errs = append(errs, fmt.Errorf("benchmark.birthdate_range_years must be positive, got %d", c.Benchmark.BirthdateRangeYears))
+	}
+	for method, fault := range c.Faults.Methods {
+		errs = append(errs, fault.validate("faults.methods."+method))
+	}
+	return errors.Join(errs...)
+}
+
+func (f FaultConfig) validate(name string) error {
+	var errs []error
+	for _, rate := range []struct {
+		name  string
+		value float64
+	}{
+		{"error_rate", f.ErrorRate},
+		{"latency_rate", f.LatencyRate},
+		{"timeout_rate", f.TimeoutRate},
+		{"partial_rate", f.PartialRate},
+	} {
+		if rate.value < 0 || rate.value > 1 {
+			errs = append(errs, fmt.Errorf("%s.%s must be between 0 and 1, got %g", name, rate.name, rate.value))
+		}
+	}
+	if f.Latency < 0 || f.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s latency and timeout must not be negative", name))
+	}
+	return errors.Join(errs...)
+}
+
+// validate reports the invalid settings of the database called name. An
+// empty DSN is only reported when requireDSN is set.
+func (d DatabaseConfig) validate(name string, requireDSN bool) error {
+	var errs []error
+	if d.DSN == "" {
+		if requireDSN {
+			errs = append(errs, fmt.Errorf("%s.dsn must be set in the config file or SQLCVSGORM_%s_DSN", name, strings.ToUpper(name)))
+		}
+	} else if u, err := url.Parse(d.DSN); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%s.dsn must be a postgres:// or postgresql:// URL with a host", name))
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("%s connection limits must not be negative", name))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("%s.max_idle_conns (%d) must not exceed max_open_conns (%d)", name, d.MaxIdleConns, d.MaxOpenConns))
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("%s connection lifetimes must not be negative", name))
+	}
+	if d.Schema != "" && !identifierPattern.MatchString(d.Schema) {
+		errs = append(errs, fmt.Errorf("%s.schema must be a lower-case identifier, got %q", name, d.Schema))
+	}
+	return errors.Join(errs...)
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// keywordPasswordPattern matches the password of a key=value connection
+// string, quoted or not.
+var keywordPasswordPattern = regexp.MustCompile(`(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// RepositoryDatabases returns the database settings each library runs
+// against under the configured isolation mode. In the single-database modes
+// GORM connects to the SQLC database but keeps its own pool settings.
+func (c Config) RepositoryDatabases() (sqlc, gorm DatabaseConfig) {
+	sqlc, gorm = c.SQLC, c.GORM
+	switch c.Isolation {
+	case IsolationSchemas:
+		gorm.DSN = sqlc.DSN
+		if sqlc.Schema == "" {
+			sqlc.Schema = DefaultSQLCSchema
+		}
+		if gorm.Schema == "" {
+			gorm.Schema = DefaultGORMSchema
+		}
+	case IsolationShared:
+		gorm.DSN = sqlc.DSN
+		gorm.Schema = sqlc.Schema
+	}
+	return sqlc, gorm
+}
+
+// Redacted returns a copy of the configuration that is safe to log, with the
+// passwords in the connection strings masked.
+func (c Config) Redacted() Config {
+	c.SQLC.DSN = RedactDSN(c.SQLC.DSN)
+	c.GORM.DSN = RedactDSN(c.GORM.DSN)
+	return c
+}
+
+// RedactDSN masks the password of a connection URL, including a password
+// passed as a query parameter, or of a key=value connection string.
+func RedactDSN(dsn string) string {
+	if !strings.Contains(dsn, "://") {
+		return keywordPasswordPattern.ReplaceAllString(dsn, "${1}xxxxx")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "<unparseable dsn>"
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", "xxxxx")
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
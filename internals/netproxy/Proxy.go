@@ -0,0 +1,216 @@
+package netproxy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/rand"
+)
+
+// Config describes the network conditions the proxy simulates. Latency and
+// jitter apply to each direction separately, so a round-trip is delayed by
+// roughly twice the configured latency.
+type Config struct {
+	// Latency is the one-way delay added to every chunk of data.
+	Latency time.Duration
+	// Jitter is the maximum random deviation, in either direction, from Latency.
+	Jitter time.Duration
+	// Bandwidth limits each direction to this many bytes per second; zero means unlimited.
+	Bandwidth int64
+}
+
+// Enabled reports whether the config simulates anything at all.
+func (c Config) Enabled() bool {
+	return c.Latency > 0 || c.Jitter > 0 || c.Bandwidth > 0
+}
+
+// Proxy forwards TCP connections to a target address under the configured
+// network conditions.
+type Proxy struct {
+	listener net.Listener
+	target   string
+	config   Config
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Start listens on listenAddr and forwards every accepted connection to
+// target. Accept and connection errors are logged to logger.
+func Start(listenAddr, target string, config Config, logger *slog.Logger) (*Proxy, error) {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+	p := &Proxy{
+		listener: listener,
+		target:   target,
+		config:   config,
+		logger:   logger,
+		rng:      rand.New(rand.NewSource(uint64(time.Now().UnixNano()))),
+		conns:    map[net.Conn]struct{}{},
+	}
+	p.wg.Add(1)
+	go p.acceptLoop()
+	return p, nil
+}
+
+// Addr returns the address the proxy listens on.
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Close stops accepting connections and closes the open ones.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+
+	err := p.listener.Close()
+	p.wg.Wait()
+	return err
+}
+
+func (p *Proxy) acceptLoop() {
+	defer p.wg.Done()
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				p.logger.Error("Accept failed", "error", err)
+			}
+			return
+		}
+		p.wg.Add(1)
+		go p.handle(client)
+	}
+}
+
+// track registers conn so Close can interrupt it; it reports false once the
+// proxy is closed.
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.conns, conn)
+}
+
+func (p *Proxy) handle(client net.Conn) {
+	defer p.wg.Done()
+	defer client.Close()
+
+	server, err := net.Dial("tcp", p.target)
+	if err != nil {
+		p.logger.Error("Failed to connect to target", "target", p.target, "error", err)
+		return
+	}
+	defer server.Close()
+
+	if !p.track(client) || !p.track(server) {
+		return
+	}
+	defer p.untrack(client)
+	defer p.untrack(server)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.pipe(server, client)
+	}()
+	go func() {
+		defer wg.Done()
+		p.pipe(client, server)
+	}()
+	wg.Wait()
+}
+
+// chunk is data read from one side, to be written to the other at deliverAt.
+type chunk struct {
+	data      []byte
+	deliverAt time.Time
+}
+
+// pipe copies src to dst, delaying every chunk by the configured latency and
+// spacing writes according to the bandwidth limit. Chunks are never reordered.
+func (p *Proxy) pipe(dst, src net.Conn) {
+	chunks := make(chan chunk, 1024)
+
+	go func() {
+		defer close(chunks)
+		var last time.Time
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := src.Read(buf)
+			if n > 0 {
+				deliverAt := time.Now().Add(p.delay())
+				if deliverAt.Before(last) {
+					deliverAt = last
+				}
+				last = deliverAt
+				chunks <- chunk{data: buf[:n], deliverAt: deliverAt}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var busyUntil time.Time
+	for c := range chunks {
+		if c.deliverAt.Before(busyUntil) {
+			c.deliverAt = busyUntil
+		}
+		time.Sleep(time.Until(c.deliverAt))
+		if _, err := dst.Write(c.data); err != nil {
+			src.Close()
+			break
+		}
+		if p.config.Bandwidth > 0 {
+			busyUntil = time.Now().Add(time.Duration(int64(len(c.data)) * int64(time.Second) / p.config.Bandwidth))
+		}
+	}
+	// Drain so the reader goroutine can exit after an early write failure
+	for range chunks {
+	}
+
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	} else {
+		dst.Close()
+	}
+}
+
+// delay returns the latency for the next chunk, including jitter.
+func (p *Proxy) delay() time.Duration {
+	d := p.config.Latency
+	if p.config.Jitter > 0 {
+		p.mu.Lock()
+		d += time.Duration(p.rng.Int63n(int64(2*p.config.Jitter)+1)) - p.config.Jitter
+		p.mu.Unlock()
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
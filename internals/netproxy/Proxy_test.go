@@ -0,0 +1,170 @@
+package netproxy
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/rand"
+)
+
+// startEcho starts a loopback server echoing everything it receives.
+func startEcho(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startProxy starts a proxy in front of an echo server and connects to it.
+func startProxy(t *testing.T, config Config) (*Proxy, net.Conn) {
+	t.Helper()
+	proxy, err := Start("127.0.0.1:0", startEcho(t), config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { proxy.Close() })
+	conn, err := net.Dial("tcp", proxy.Addr())
+	if err != nil {
+		t.Fatalf("failed to connect to the proxy: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return proxy, conn
+}
+
+// echo sends size bytes through conn and waits for them to come back.
+func echo(t *testing.T, conn net.Conn, size int) {
+	t.Helper()
+	if _, err := conn.Write(make([]byte, size)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, size)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	const latency = 25 * time.Millisecond
+	_, conn := startProxy(t, Config{Latency: latency})
+	// The first exchange includes connecting to the echo server
+	echo(t, conn, 1)
+
+	for range 3 {
+		start := time.Now()
+		echo(t, conn, 16)
+		if elapsed := time.Since(start); elapsed < 2*latency {
+			t.Errorf("round-trip took %v, want at least %v", elapsed, 2*latency)
+		}
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	const (
+		bandwidth = 10_000
+		size      = 500
+		exchanges = 3
+	)
+	_, conn := startProxy(t, Config{Bandwidth: bandwidth})
+
+	// Sending size bytes keeps each direction busy for size/bandwidth, so every
+	// exchange after the first waits for the previous one to drain
+	start := time.Now()
+	for range exchanges {
+		echo(t, conn, size)
+	}
+	want := (exchanges - 1) * time.Duration(size) * time.Second / bandwidth
+	if elapsed := time.Since(start); elapsed < want {
+		t.Errorf("%d exchanges of %d bytes took %v, want at least %v", exchanges, size, elapsed, want)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		min, max time.Duration
+	}{
+		{name: "latency only", config: Config{Latency: 10 * time.Millisecond}, min: 10 * time.Millisecond, max: 10 * time.Millisecond},
+		{name: "jitter around latency", config: Config{Latency: 10 * time.Millisecond, Jitter: 5 * time.Millisecond}, min: 5 * time.Millisecond, max: 15 * time.Millisecond},
+		{name: "jitter clamped at zero", config: Config{Latency: 2 * time.Millisecond, Jitter: 10 * time.Millisecond}, min: 0, max: 12 * time.Millisecond},
+		{name: "jitter only", config: Config{Jitter: time.Millisecond}, min: 0, max: time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Proxy{config: tt.config, rng: rand.New(rand.NewSource(1))}
+			var sawMin bool
+			for range 1000 {
+				d := p.delay()
+				if d < tt.min || d > tt.max {
+					t.Fatalf("delay = %v, want between %v and %v", d, tt.min, tt.max)
+				}
+				sawMin = sawMin || d == tt.min
+			}
+			if tt.min == 0 && !sawMin {
+				t.Errorf("delay never clamped to 0")
+			}
+		})
+	}
+}
+
+func TestCloseUnblocksOpenConnections(t *testing.T) {
+	proxy, conn := startProxy(t, Config{Latency: time.Millisecond})
+	echo(t, conn, 1)
+
+	// The connection is idle, so both pipes are blocked reading
+	closed := make(chan error, 1)
+	go func() { closed <- proxy.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return while a connection was open")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("read from a connection of a closed proxy succeeded")
+	} else if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		t.Error("connection stayed open after Close")
+	}
+
+	if _, err := net.DialTimeout("tcp", proxy.Addr(), time.Second); err == nil {
+		t.Error("proxy accepted a connection after Close")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		config Config
+		want   bool
+	}{
+		{Config{}, false},
+		{Config{Latency: time.Millisecond}, true},
+		{Config{Jitter: time.Millisecond}, true},
+		{Config{Bandwidth: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.config.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %t, want %t", tt.config, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuthorSortField is a column FilterAuthors can sort by.
+type AuthorSortField string
+
+const (
+	SortByName        AuthorSortField = "name"
+	SortByEmail       AuthorSortField = "email"
+	SortByDateOfBirth AuthorSortField = "date_of_birth"
+	SortByID          AuthorSortField = "id"
+)
+
+// SortDirection is the order FilterAuthors sorts in.
+type SortDirection string
+
+const (
+	Ascending  SortDirection = "asc"
+	Descending SortDirection = "desc"
+)
+
+// AuthorFilter selects and orders the authors returned by FilterAuthors.
+// Zero values leave the corresponding criterion out.
+type AuthorFilter struct {
+	// NamePrefix matches names starting with it, case-sensitively.
+	NamePrefix string
+	// EmailDomain matches emails whose part after the @ equals it.
+	EmailDomain string
+	// HasBio matches authors with a non-empty bio when true and authors
+	// without one when false.
+	HasBio *bool
+	// BornAfter and BornBefore bound the date of birth, inclusively.
+	BornAfter  time.Time
+	BornBefore time.Time
+	// SortBy defaults to SortByName and Direction to Ascending. Ties are
+	// broken by ID in the same direction.
+	SortBy    AuthorSortField
+	Direction SortDirection
+}
+
+// sort returns the validated sort field and whether to sort descending.
+func (f AuthorFilter) sort() (AuthorSortField, bool, error) {
+	field := f.SortBy
+	switch field {
+	case "":
+		field = SortByName
+	case SortByName, SortByEmail, SortByDateOfBirth, SortByID:
+	default:
+		return "", false, fmt.Errorf("invalid author sort field %q", f.SortBy)
+	}
+	switch f.Direction {
+	case "", Ascending:
+		return field, false, nil
+	case Descending:
+		return field, true, nil
+	default:
+		return "", false, fmt.Errorf("invalid sort direction %q", f.Direction)
+	}
+}
@@ -16,4 +16,8 @@ type AuthorRepository interface {
 	DeleteAuthor(ctx context.Context, id int32) error
 	UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error
 	GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error)
+	// SearchAuthors returns at most limit authors whose name or bio match the
+	// web-search style query, best ts_rank first.
+	SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error)
+	FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error)
 }
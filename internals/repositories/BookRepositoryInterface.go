@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// BookDetails is a book loaded together with its publisher and authors.
+type BookDetails struct {
+	sqlcgen.Book
+	Publisher sqlcgen.Publisher `json:"publisher"`
+	Authors   []sqlcgen.Author  `json:"authors"`
+}
+
+// AuthorWithBooks is an author loaded together with the books they wrote.
+type AuthorWithBooks struct {
+	sqlcgen.Author
+	Books []sqlcgen.Book `json:"books"`
+}
+
+// appendAuthorBook adds book to the last author of authors, or starts a new
+// author when the rows moved on to another one. Rows must be grouped by author.
+func appendAuthorBook(authors []AuthorWithBooks, author sqlcgen.Author, book sqlcgen.Book) []AuthorWithBooks {
+	if n := len(authors); n > 0 && authors[n-1].ID == author.ID {
+		authors[n-1].Books = append(authors[n-1].Books, book)
+		return authors
+	}
+	return append(authors, AuthorWithBooks{Author: author, Books: []sqlcgen.Book{book}})
+}
+
+// BookRepository defines the methods for publishers, books and their authors
+// that both GORM and SQLC repositories must implement.
+type BookRepository interface {
+	CreatePublisher(ctx context.Context, name string, country sql.NullString) (int32, error)
+	GetPublisher(ctx context.Context, id int32) (sqlcgen.Publisher, error)
+	DeletePublisher(ctx context.Context, id int32) error
+	// CreateBook inserts the book and links it to authorIDs in one transaction.
+	CreateBook(ctx context.Context, publisherID int32, title, isbn string, publishedOn sql.NullTime, authorIDs []int32) (int32, error)
+	GetBook(ctx context.Context, id int32) (BookDetails, error)
+	ListBooksByAuthor(ctx context.Context, authorID int32) ([]sqlcgen.Book, error)
+	ListBooksByPublisher(ctx context.Context, publisherID int32) ([]sqlcgen.Book, error)
+	DeleteBook(ctx context.Context, id int32) error
+	// ListAuthorsWithBooks loads the given authors that have books, ordered
+	// by name, with their books ordered by title, using each library's
+	// idiomatic strategy.
+	ListAuthorsWithBooks(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error)
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/cache"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache keys of the entries stored by CachingRepository.
+const (
+	authorCacheKeyPrefix = "author:"
+	authorsCacheKey      = "authors"
+)
+
+// CacheStats counts the lookups made by a CachingRepository.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Loads is the number of misses that read from the wrapped repository.
+	// Concurrent misses of the same entry share one load.
+	Loads uint64
+}
+
+// HitRatio returns the share of lookups served from the cache.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// CachingRepository serves GetAuthor and ListAuthors from a cache.Cache,
+// reading through to the wrapped repository on a miss, and drops the
+// affected entries on every CreateAuthor, UpdateAuthor and DeleteAuthor.
+// Other methods are passed through.
+//
+// Entries are stored as JSON, so every caller gets its own copy. Invalidation
+// only covers writes made through the same CachingRepository; writes made
+// elsewhere show up once the entries expire.
+type CachingRepository struct {
+	next  AuthorRepository
+	cache cache.Cache
+	ttl   time.Duration
+	group singleflight.Group
+
+	// generation is incremented by every write, so that a load that raced
+	// with a write does not keep what it read before the write
+	generation atomic.Uint64
+
+	hits, misses, loads atomic.Uint64
+}
+
+// NewCachingRepository wraps next in a CachingRepository storing entries in
+// c for ttl, or until evicted when ttl is zero.
+func NewCachingRepository(next AuthorRepository, c cache.Cache, ttl time.Duration) *CachingRepository {
+	return &CachingRepository{next: next, cache: c, ttl: ttl}
+}
+
+// Stats returns the lookups made so far.
+func (r *CachingRepository) Stats() CacheStats {
+	return CacheStats{Hits: r.hits.Load(), Misses: r.misses.Load(), Loads: r.loads.Load()}
+}
+
+func authorCacheKey(id int32) string {
+	return authorCacheKeyPrefix + strconv.Itoa(int(id))
+}
+
+// readThrough returns the value cached under key, or loads, caches and
+// returns it. Cache failures are treated as misses.
+func readThrough[T any](ctx context.Context, r *CachingRepository, key string, load func(context.Context) (T, error)) (T, error) {
+	var value T
+	if data, ok, err := r.cache.Get(ctx, key); err == nil && ok && json.Unmarshal(data, &value) == nil {
+		r.hits.Add(1)
+		return value, nil
+	}
+	r.misses.Add(1)
+
+	// Callers arriving after a write must not join a load started before it
+	generation := r.generation.Load()
+	flightKey := key + "@" + strconv.FormatUint(generation, 10)
+	result := r.group.DoChan(flightKey, func() (any, error) {
+		r.loads.Add(1)
+		// The load is shared by every caller waiting for it, so it must not
+		// be canceled along with the one that started it
+		loadCtx := context.WithoutCancel(ctx)
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cached authors: %w", err)
+		}
+		if r.generation.Load() == generation {
+			// A failure to store only costs a later miss
+			_ = r.cache.Set(loadCtx, key, data, r.ttl)
+			// A write between the check and the Set may have invalidated
+			// the entry before it was stored
+			if r.generation.Load() != generation {
+				_ = r.cache.Delete(loadCtx, key)
+			}
+		}
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return value, res.Err
+		}
+		err := json.Unmarshal(res.Val.([]byte), &value)
+		return value, err
+	}
+}
+
+// invalidate drops the entries of keys after a write. It runs whether or not
+// the write failed, since a failed write may still have been applied.
+func (r *CachingRepository) invalidate(ctx context.Context, writeErr error, keys ...string) error {
+	r.generation.Add(1)
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		return errors.Join(writeErr, fmt.Errorf("failed to invalidate cached authors: %w", err))
+	}
+	return writeErr
+}
+
+func (r *CachingRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	id, err := r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+	return id, r.invalidate(ctx, err, authorsCacheKey)
+}
+
+func (r *CachingRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	return readThrough(ctx, r, authorCacheKey(id), func(ctx context.Context) (sqlcgen.Author, error) {
+		return r.next.GetAuthor(ctx, id)
+	})
+}
+
+func (r *CachingRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	return readThrough(ctx, r, authorsCacheKey, r.next.ListAuthors)
+}
+
+func (r *CachingRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	err := r.next.DeleteAuthor(ctx, id)
+	return r.invalidate(ctx, err, authorCacheKey(id), authorsCacheKey)
+}
+
+func (r *CachingRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	err := r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+	return r.invalidate(ctx, err, authorCacheKey(id), authorsCacheKey)
+}
+
+func (r *CachingRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	return r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+}
+
+func (r *CachingRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	return r.next.SearchAuthors(ctx, query, limit)
+}
+
+func (r *CachingRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	return r.next.FilterAuthors(ctx, filter)
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/cache"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// gatedRepository holds every GetAuthor after it has read the author until
+// release is closed, so that tests can act while loads are in flight.
+type gatedRepository struct {
+	AuthorRepository
+	loaded  chan struct{}
+	release chan struct{}
+	loads   atomic.Int32
+}
+
+func newGatedRepository(next AuthorRepository) *gatedRepository {
+	return &gatedRepository{AuthorRepository: next, loaded: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (r *gatedRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	r.loads.Add(1)
+	author, err := r.AuthorRepository.GetAuthor(ctx, id)
+	r.loaded <- struct{}{}
+	<-r.release
+	return author, err
+}
+
+// newCachedAuthor returns a MemoryRepository holding one author named Ann.
+func newCachedAuthor(t *testing.T) (*MemoryRepository, int32) {
+	t.Helper()
+	memory := NewMemoryRepository()
+	id, err := memory.CreateAuthor(context.Background(), "Ann", sql.NullString{}, "ann@example.com", sql.NullTime{})
+	if err != nil {
+		t.Fatalf("CreateAuthor failed: %v", err)
+	}
+	return memory, id
+}
+
+func assertAuthorName(t *testing.T, repo AuthorRepository, id int32, want string) {
+	t.Helper()
+	author, err := repo.GetAuthor(context.Background(), id)
+	if err != nil || author.Name != want {
+		t.Errorf("GetAuthor = %q, %v, want %q", author.Name, err, want)
+	}
+}
+
+func TestCachingRepositoryReadsThrough(t *testing.T) {
+	memory, id := newCachedAuthor(t)
+	repo := NewCachingRepository(memory, cache.NewLRUCache(10), 0)
+
+	assertAuthorName(t, repo, id, "Ann")
+	assertAuthorName(t, repo, id, "Ann")
+	if stats := repo.Stats(); stats != (CacheStats{Hits: 1, Misses: 1, Loads: 1}) {
+		t.Errorf("stats = %+v, want 1 hit, 1 miss and 1 load", stats)
+	}
+
+	if err := repo.UpdateAuthor(context.Background(), id, "Anne", sql.NullString{}, "ann@example.com", sql.NullTime{}); err != nil {
+		t.Fatalf("UpdateAuthor failed: %v", err)
+	}
+	assertAuthorName(t, repo, id, "Anne")
+	if _, err := repo.GetAuthor(context.Background(), id+1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAuthor of a missing author = %v, want %v", err, sql.ErrNoRows)
+	}
+	if stats := repo.Stats(); stats.Loads != 3 {
+		t.Errorf("loads = %d, want 3", stats.Loads)
+	}
+}
+
+func TestCachingRepositoryWriteInvalidatesRacingLoad(t *testing.T) {
+	memory, id := newCachedAuthor(t)
+	gated := newGatedRepository(memory)
+	repo := NewCachingRepository(gated, cache.NewLRUCache(10), 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// This caller may see the author as it was when the load read it
+		if _, err := repo.GetAuthor(context.Background(), id); err != nil {
+			t.Errorf("GetAuthor failed: %v", err)
+		}
+	}()
+
+	// The load has read Ann, but not stored it yet
+	<-gated.loaded
+	if err := repo.UpdateAuthor(context.Background(), id, "Anne", sql.NullString{}, "ann@example.com", sql.NullTime{}); err != nil {
+		t.Fatalf("UpdateAuthor failed: %v", err)
+	}
+	close(gated.release)
+	<-done
+
+	assertAuthorName(t, repo, id, "Anne")
+	if loads := gated.loads.Load(); loads != 2 {
+		t.Errorf("loads = %d, want the stale author not to be cached", loads)
+	}
+}
+
+func TestCachingRepositorySharesConcurrentLoads(t *testing.T) {
+	memory, id := newCachedAuthor(t)
+	gated := newGatedRepository(memory)
+	repo := NewCachingRepository(gated, cache.NewLRUCache(10), 0)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assertAuthorName(t, repo, id, "Ann")
+		}()
+	}
+
+	// Hold the load until every caller has missed and joined it
+	<-gated.loaded
+	for repo.Stats().Misses < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(gated.release)
+	wg.Wait()
+
+	if loads := gated.loads.Load(); loads != 1 {
+		t.Errorf("loads = %d, want 1 shared by %d callers", loads, callers)
+	}
+}
+
+func TestCachingRepositoryExpiresEntries(t *testing.T) {
+	memory, id := newCachedAuthor(t)
+	repo := NewCachingRepository(memory, cache.NewLRUCache(10), 10*time.Millisecond)
+
+	assertAuthorName(t, repo, id, "Ann")
+	assertAuthorName(t, repo, id, "Ann")
+	time.Sleep(20 * time.Millisecond)
+	assertAuthorName(t, repo, id, "Ann")
+	if stats := repo.Stats(); stats != (CacheStats{Hits: 1, Misses: 2, Loads: 2}) {
+		t.Errorf("stats = %+v, want the expired entry to be loaded again", stats)
+	}
+}
+
+func TestCachingRepositoryEvictsLeastRecentlyUsed(t *testing.T) {
+	memory, ann := newCachedAuthor(t)
+	bob, err := memory.CreateAuthor(context.Background(), "Bob", sql.NullString{}, "bob@example.com", sql.NullTime{})
+	if err != nil {
+		t.Fatalf("CreateAuthor failed: %v", err)
+	}
+	repo := NewCachingRepository(memory, cache.NewLRUCache(1), 0)
+
+	assertAuthorName(t, repo, ann, "Ann")
+	assertAuthorName(t, repo, bob, "Bob") // evicts Ann
+	assertAuthorName(t, repo, bob, "Bob")
+	assertAuthorName(t, repo, ann, "Ann")
+	if stats := repo.Stats(); stats != (CacheStats{Hits: 1, Misses: 3, Loads: 3}) {
+		t.Errorf("stats = %+v, want Ann loaded again after being evicted", stats)
+	}
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// ContextRepository tags the context of every call with the name of the
+// method, and with a new request ID unless the caller already set one, so
+// that decorators and loggers further down can correlate what they emit.
+type ContextRepository struct {
+	next AuthorRepository
+}
+
+// NewContextRepository wraps next in a ContextRepository.
+func NewContextRepository(next AuthorRepository) *ContextRepository {
+	return &ContextRepository{next: next}
+}
+
+// withOperation returns ctx carrying operation and a request ID.
+func withOperation(ctx context.Context, operation string) context.Context {
+	if pkgs.RequestIDFromContext(ctx) == "" {
+		ctx = pkgs.ContextWithRequestID(ctx, pkgs.NewRequestID())
+	}
+	return pkgs.ContextWithOperation(ctx, operation)
+}
+
+func (r *ContextRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	return r.next.CreateAuthor(withOperation(ctx, "CreateAuthor"), name, bio, email, dateOfBirth)
+}
+
+func (r *ContextRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	return r.next.GetAuthor(withOperation(ctx, "GetAuthor"), id)
+}
+
+func (r *ContextRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	return r.next.ListAuthors(withOperation(ctx, "ListAuthors"))
+}
+
+func (r *ContextRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	return r.next.DeleteAuthor(withOperation(ctx, "DeleteAuthor"), id)
+}
+
+func (r *ContextRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	return r.next.UpdateAuthor(withOperation(ctx, "UpdateAuthor"), id, name, bio, email, dateOfBirth)
+}
+
+func (r *ContextRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	return r.next.GetAuthorsByBirthdateRange(withOperation(ctx, "GetAuthorsByBirthdateRange"), startDate, endDate)
+}
+
+func (r *ContextRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	return r.next.SearchAuthors(withOperation(ctx, "SearchAuthors"), query, limit)
+}
+
+func (r *ContextRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	return r.next.FilterAuthors(withOperation(ctx, "FilterAuthors"), filter)
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// TestContextRepositoryOutsideRetries checks that the request ID of a call
+// reaches the log lines of every attempt when the ContextRepository wraps the
+// ResilientRepository, as in main.
+func TestContextRepositoryOutsideRetries(t *testing.T) {
+	injector := &faultInjector{}
+	handler := &captureHandler{level: slog.LevelDebug}
+	logger := slog.New(pkgs.NewContextHandler(handler))
+	repo := NewContextRepository(NewResilientRepository(
+		NewLoggingRepository(injector, logger, LoggingConfig{Level: slog.LevelInfo}),
+		ResilienceConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	))
+
+	injector.inject(errSerialization, errDeadlock)
+	if _, err := repo.GetAuthor(context.Background(), 1); err != nil {
+		t.Fatalf("GetAuthor failed: %v", err)
+	}
+	if _, err := repo.ListAuthors(context.Background()); err != nil {
+		t.Fatalf("ListAuthors failed: %v", err)
+	}
+
+	records := handler.all()
+	if len(records) != 4 {
+		t.Fatalf("logged %d records, want 3 attempts of GetAuthor and 1 of ListAuthors", len(records))
+	}
+	var ids []string
+	for i, record := range records {
+		attrs := recordAttrs(record)
+		if attrs[pkgs.RequestIDKey] == "" {
+			t.Errorf("record %d has no request ID", i)
+		}
+		ids = append(ids, attrs[pkgs.RequestIDKey])
+		wantOperation := "GetAuthor"
+		if i == 3 {
+			wantOperation = "ListAuthors"
+		}
+		if got := attrs[pkgs.OperationKey]; got != wantOperation {
+			t.Errorf("record %d operation = %q, want %q", i, got, wantOperation)
+		}
+	}
+	if ids[0] != ids[1] || ids[1] != ids[2] {
+		t.Errorf("attempts of one call logged request IDs %v, want one ID", ids[:3])
+	}
+	if ids[3] == ids[0] {
+		t.Errorf("two calls share request ID %s", ids[0])
+	}
+	if records[0].Level != slog.LevelError || records[2].Level != slog.LevelInfo {
+		t.Errorf("attempts logged at %v, %v and %v, want two errors then info", records[0].Level, records[1].Level, records[2].Level)
+	}
+}
+
+func TestContextRepositoryKeepsCallerRequestID(t *testing.T) {
+	handler := &captureHandler{level: slog.LevelDebug}
+	logger := slog.New(pkgs.NewContextHandler(handler))
+	repo := NewContextRepository(NewLoggingRepository(&faultInjector{}, logger, LoggingConfig{Level: slog.LevelInfo}))
+
+	ctx := pkgs.ContextWithRequestID(context.Background(), "caller-id")
+	if err := repo.DeleteAuthor(ctx, 1); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	records := handler.all()
+	if len(records) != 1 {
+		t.Fatalf("logged %d records, want 1", len(records))
+	}
+	attrs := recordAttrs(records[0])
+	if attrs[pkgs.RequestIDKey] != "caller-id" || attrs[pkgs.OperationKey] != "DeleteAuthor" {
+		t.Errorf("logged request ID %q and operation %q, want caller-id and DeleteAuthor", attrs[pkgs.RequestIDKey], attrs[pkgs.OperationKey])
+	}
+}
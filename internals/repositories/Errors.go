@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Kinds of errors returned by ErrorKind. They are coarse enough to be used as
+// metric labels.
+const (
+	ErrorKindNotFound      = "not_found"
+	ErrorKindCanceled      = "canceled"
+	ErrorKindTimeout       = "timeout"
+	ErrorKindConnection    = "connection"
+	ErrorKindSerialization = "serialization"
+	ErrorKindConstraint    = "constraint"
+	ErrorKindQuery         = "query"
+	ErrorKindOther         = "other"
+)
+
+// ErrorKind classifies an error returned by either repository implementation.
+// Both report the same kinds although sqlc goes through lib/pq and GORM
+// through pgx.
+func ErrorKind(err error) string {
+	switch code := SQLState(err); {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrorKindNotFound
+	case errors.Is(err, context.Canceled), code == "57014":
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case code == "40001", code == "40P01":
+		// serialization_failure and deadlock_detected
+		return ErrorKindSerialization
+	case strings.HasPrefix(code, "23"):
+		return ErrorKindConstraint
+	case strings.HasPrefix(code, "08"), code == "57P01", code == "57P02", code == "57P03":
+		// connection_exception and the server shutting down or starting up
+		return ErrorKindConnection
+	case code != "":
+		return ErrorKindQuery
+	case isConnectionError(err):
+		return ErrorKindConnection
+	default:
+		return ErrorKindOther
+	}
+}
+
+// SQLState returns the SQLSTATE code of a PostgreSQL error returned through
+// lib/pq or pgx, or "" if err does not come from the server.
+func SQLState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// isConnectionError reports whether err comes from a broken or unreachable
+// connection rather than from the server.
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) ||
+		errors.As(err, &connectErr)
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"sql no rows", sql.ErrNoRows, ErrorKindNotFound},
+		{"wrapped sql no rows", fmt.Errorf("failed to get author: %w", sql.ErrNoRows), ErrorKindNotFound},
+		{"gorm record not found", gorm.ErrRecordNotFound, ErrorKindNotFound},
+		{"canceled", context.Canceled, ErrorKindCanceled},
+		{"query canceled by the server", &pq.Error{Code: "57014"}, ErrorKindCanceled},
+		{"deadline exceeded", context.DeadlineExceeded, ErrorKindTimeout},
+		{"wrapped deadline exceeded", fmt.Errorf("call failed: %w", context.DeadlineExceeded), ErrorKindTimeout},
+		{"pq unique violation", errUniqueEmail, ErrorKindConstraint},
+		{"pgx unique violation", &pgconn.PgError{Code: "23505"}, ErrorKindConstraint},
+		{"wrapped pgx unique violation", fmt.Errorf("failed to create author: %w", &pgconn.PgError{Code: "23505"}), ErrorKindConstraint},
+		{"pgx foreign key violation", &pgconn.PgError{Code: "23503"}, ErrorKindConstraint},
+		{"pq serialization failure", errSerialization, ErrorKindSerialization},
+		{"pgx deadlock", errDeadlock, ErrorKindSerialization},
+		{"pq connection failure", &pq.Error{Code: "08006"}, ErrorKindConnection},
+		{"pgx admin shutdown", &pgconn.PgError{Code: "57P01"}, ErrorKindConnection},
+		{"undefined table", &pq.Error{Code: "42P01"}, ErrorKindQuery},
+		{"bad connection", driver.ErrBadConn, ErrorKindConnection},
+		{"unexpected EOF", fmt.Errorf("read failed: %w", io.ErrUnexpectedEOF), ErrorKindConnection},
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, ErrorKindConnection},
+		{"other", errors.New("something else"), ErrorKindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorKind(tt.err); got != tt.want {
+				t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLState(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errUniqueEmail, "23505"},
+		{errDeadlock, "40P01"},
+		{fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "42P01"}), "42P01"},
+		{sql.ErrNoRows, ""},
+		{io.EOF, ""},
+	}
+	for _, tt := range tests {
+		if got := SQLState(tt.err); got != tt.want {
+			t.Errorf("SQLState(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// ErrInjectedFault is wrapped by every error injected by a FaultyRepository.
+var ErrInjectedFault = errors.New("injected fault")
+
+// AllMethods is the FaultyRepository key of the faults injected into the
+// methods that have none of their own.
+const AllMethods = "*"
+
+// InjectedError returns an error that ErrorKind classifies as kind and that
+// wraps ErrInjectedFault, reporting false for kinds it cannot produce.
+// Database errors carry the SQLSTATE PostgreSQL would send.
+func InjectedError(kind string) (error, bool) {
+	var err error
+	switch kind {
+	case ErrorKindNotFound:
+		err = sql.ErrNoRows
+	case ErrorKindCanceled:
+		err = context.Canceled
+	case ErrorKindTimeout:
+		err = context.DeadlineExceeded
+	case ErrorKindConnection:
+		err = driver.ErrBadConn
+	case ErrorKindSerialization:
+		err = &pgconn.PgError{Severity: "ERROR", Code: "40001", Message: "could not serialize access due to concurrent update"}
+	case ErrorKindConstraint:
+		err = &pgconn.PgError{Severity: "ERROR", Code: "23505", Message: "duplicate key value violates unique constraint"}
+	case ErrorKindQuery:
+		err = &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: "syntax error"}
+	default:
+		return nil, false
+	}
+	return fmt.Errorf("%w: %w", ErrInjectedFault, err), true
+}
+
+// Fault describes the faults injected into the calls of one method. Each
+// kind of fault hits a call with its own probability, between 0 and 1.
+type Fault struct {
+	// ErrorRate is the probability that a call fails with Err without
+	// reaching the wrapped repository. Err defaults to a connection error
+	// and is wrapped in ErrInjectedFault.
+	ErrorRate float64
+	Err       error
+	// LatencyRate is the probability that a call is delayed by Latency
+	// before reaching the wrapped repository.
+	LatencyRate float64
+	Latency     time.Duration
+	// TimeoutRate is the probability that a call hangs, as one waiting on a
+	// lock would, until its context is done or Timeout has passed, and then
+	// fails with context.DeadlineExceeded. Timeout defaults to 1s.
+	TimeoutRate float64
+	Timeout     time.Duration
+	// PartialRate is the probability that a method returning a list of
+	// authors returns only a random part of it, without error.
+	PartialRate float64
+}
+
+// FaultStats counts the faults injected by a FaultyRepository.
+type FaultStats struct {
+	Errors   uint64
+	Delays   uint64
+	Timeouts uint64
+	Partials uint64
+}
+
+// FaultyRepository injects errors, latency, timeouts and partial results into
+// the calls to the wrapped repository, to exercise callers and decorators
+// under failure without breaking a database.
+//
+// Faults are drawn from a random source seeded by the caller, so a sequence
+// of calls made in the same order sees the same faults on every run.
+type FaultyRepository struct {
+	next   AuthorRepository
+	faults map[string]Fault
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	errors, delays, timeouts, partials atomic.Uint64
+}
+
+// NewFaultyRepository wraps next in a FaultyRepository injecting faults,
+// keyed by method name or AllMethods, with a random source seeded by seed.
+func NewFaultyRepository(next AuthorRepository, seed uint64, faults map[string]Fault) *FaultyRepository {
+	return &FaultyRepository{
+		next:   next,
+		faults: faults,
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Stats returns the faults injected so far.
+func (r *FaultyRepository) Stats() FaultStats {
+	return FaultStats{
+		Errors:   r.errors.Load(),
+		Delays:   r.delays.Load(),
+		Timeouts: r.timeouts.Load(),
+		Partials: r.partials.Load(),
+	}
+}
+
+func (r *FaultyRepository) fault(method string) Fault {
+	if fault, ok := r.faults[method]; ok {
+		return fault
+	}
+	return r.faults[AllMethods]
+}
+
+// hit reports whether a fault with the given probability hits the call.
+func (r *FaultyRepository) hit(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64() < probability
+}
+
+// inject applies the latency, timeout and error faults of method, returning
+// the error the call must fail with, if any.
+func (r *FaultyRepository) inject(ctx context.Context, method string) error {
+	fault := r.fault(method)
+	if r.hit(fault.LatencyRate) {
+		r.delays.Add(1)
+		if err := wait(ctx, fault.Latency); err != nil {
+			return err
+		}
+	}
+	if r.hit(fault.TimeoutRate) {
+		r.timeouts.Add(1)
+		timeout := fault.Timeout
+		if timeout <= 0 {
+			timeout = time.Second
+		}
+		if err := wait(ctx, timeout); err != nil {
+			return fmt.Errorf("%w: %w", ErrInjectedFault, err)
+		}
+		return fmt.Errorf("%w: %w", ErrInjectedFault, context.DeadlineExceeded)
+	}
+	if r.hit(fault.ErrorRate) {
+		r.errors.Add(1)
+		if fault.Err != nil {
+			if errors.Is(fault.Err, ErrInjectedFault) {
+				return fault.Err
+			}
+			return fmt.Errorf("%w: %w", ErrInjectedFault, fault.Err)
+		}
+		err, _ := InjectedError(ErrorKindConnection)
+		return err
+	}
+	return nil
+}
+
+// truncate applies the partial result fault of method to authors.
+func (r *FaultyRepository) truncate(method string, authors []sqlcgen.Author) []sqlcgen.Author {
+	if len(authors) == 0 || !r.hit(r.fault(method).PartialRate) {
+		return authors
+	}
+	r.partials.Add(1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return authors[:r.rng.IntN(len(authors))]
+}
+
+// wait waits for d or until ctx is done, returning the context's error in
+// the latter case.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (r *FaultyRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	if err := r.inject(ctx, "CreateAuthor"); err != nil {
+		return 0, err
+	}
+	return r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+}
+
+func (r *FaultyRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	if err := r.inject(ctx, "GetAuthor"); err != nil {
+		return sqlcgen.Author{}, err
+	}
+	return r.next.GetAuthor(ctx, id)
+}
+
+func (r *FaultyRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	if err := r.inject(ctx, "ListAuthors"); err != nil {
+		return nil, err
+	}
+	authors, err := r.next.ListAuthors(ctx)
+	return r.truncate("ListAuthors", authors), err
+}
+
+func (r *FaultyRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	if err := r.inject(ctx, "DeleteAuthor"); err != nil {
+		return err
+	}
+	return r.next.DeleteAuthor(ctx, id)
+}
+
+func (r *FaultyRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	if err := r.inject(ctx, "UpdateAuthor"); err != nil {
+		return err
+	}
+	return r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+}
+
+func (r *FaultyRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	if err := r.inject(ctx, "GetAuthorsByBirthdateRange"); err != nil {
+		return nil, err
+	}
+	authors, err := r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+	return r.truncate("GetAuthorsByBirthdateRange", authors), err
+}
+
+func (r *FaultyRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	if err := r.inject(ctx, "SearchAuthors"); err != nil {
+		return nil, err
+	}
+	authors, err := r.next.SearchAuthors(ctx, query, limit)
+	return r.truncate("SearchAuthors", authors), err
+}
+
+func (r *FaultyRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	if err := r.inject(ctx, "FilterAuthors"); err != nil {
+		return nil, err
+	}
+	authors, err := r.next.FilterAuthors(ctx, filter)
+	return r.truncate("FilterAuthors", authors), err
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInjectedError(t *testing.T) {
+	kinds := []string{
+		ErrorKindNotFound,
+		ErrorKindCanceled,
+		ErrorKindTimeout,
+		ErrorKindConnection,
+		ErrorKindSerialization,
+		ErrorKindConstraint,
+		ErrorKindQuery,
+	}
+	for _, kind := range kinds {
+		t.Run(kind, func(t *testing.T) {
+			err, ok := InjectedError(kind)
+			if !ok {
+				t.Fatalf("InjectedError(%q) not supported", kind)
+			}
+			if got := ErrorKind(err); got != kind {
+				t.Errorf("ErrorKind(%v) = %s, want %s", err, got, kind)
+			}
+			if !errors.Is(err, ErrInjectedFault) {
+				t.Errorf("%v does not wrap ErrInjectedFault", err)
+			}
+		})
+	}
+	if _, ok := InjectedError(ErrorKindOther); ok {
+		t.Errorf("InjectedError(%q) supported", ErrorKindOther)
+	}
+}
+
+func TestFaultyRepositoryErrors(t *testing.T) {
+	next := &faultInjector{}
+	repo := NewFaultyRepository(next, 1, map[string]Fault{
+		AllMethods:  {ErrorRate: 1, Err: errSerialization},
+		"GetAuthor": {},
+	})
+
+	if err := updateAuthor(repo); !errors.Is(err, errSerialization) || !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("UpdateAuthor err = %v, want an injected %v", err, errSerialization)
+	}
+	if _, err := repo.ListAuthors(context.Background()); !errors.Is(err, errSerialization) {
+		t.Errorf("ListAuthors err = %v, want %v", err, errSerialization)
+	}
+	if err := getAuthor(repo); err != nil {
+		t.Errorf("GetAuthor err = %v, want none", err)
+	}
+	if calls := next.callCount(); calls != 1 {
+		t.Errorf("calls = %d, want only GetAuthor to reach the wrapped repository", calls)
+	}
+	if stats := repo.Stats(); stats != (FaultStats{Errors: 2}) {
+		t.Errorf("stats = %+v, want 2 errors", stats)
+	}
+}
+
+func TestFaultyRepositoryDefaultError(t *testing.T) {
+	repo := NewFaultyRepository(&faultInjector{}, 1, map[string]Fault{AllMethods: {ErrorRate: 1}})
+	err := getAuthor(repo)
+	if !errors.Is(err, ErrInjectedFault) || ErrorKind(err) != ErrorKindConnection {
+		t.Errorf("err = %v, want an injected connection error", err)
+	}
+}
+
+func TestFaultyRepositoryIsDeterministic(t *testing.T) {
+	faults := map[string]Fault{AllMethods: {ErrorRate: 0.5}}
+	pattern := func(seed uint64) []bool {
+		repo := NewFaultyRepository(&faultInjector{}, seed, faults)
+		failed := make([]bool, 100)
+		for i := range failed {
+			failed[i] = getAuthor(repo) != nil
+		}
+		return failed
+	}
+
+	first, again, other := pattern(1), pattern(1), pattern(2)
+	var failures int
+	for i := range first {
+		if first[i] != again[i] {
+			t.Fatalf("call %d: seed 1 injected different faults on two runs", i)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures < 30 || failures > 70 {
+		t.Errorf("failures = %d of 100, want about 50", failures)
+	}
+	if slices.Equal(first, other) {
+		t.Errorf("seeds 1 and 2 injected the same faults")
+	}
+}
+
+func TestFaultyRepositoryLatency(t *testing.T) {
+	repo := NewFaultyRepository(&faultInjector{}, 1, map[string]Fault{
+		AllMethods: {LatencyRate: 1, Latency: 20 * time.Millisecond},
+	})
+	start := time.Now()
+	if err := getAuthor(repo); err != nil {
+		t.Fatalf("GetAuthor failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.GetAuthor(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFaultyRepositoryTimeout(t *testing.T) {
+	next := &faultInjector{}
+	repo := NewFaultyRepository(next, 1, map[string]Fault{
+		AllMethods: {TimeoutRate: 1, Timeout: time.Hour},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := repo.GetAuthor(ctx, 1); !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("err = %v, want an injected deadline error", err)
+	}
+
+	// Without a deadline the call gives up after Timeout
+	repo = NewFaultyRepository(next, 1, map[string]Fault{
+		AllMethods: {TimeoutRate: 1, Timeout: time.Millisecond},
+	})
+	if err := getAuthor(repo); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if calls := next.callCount(); calls != 0 {
+		t.Errorf("calls = %d, want timed out calls not to reach the wrapped repository", calls)
+	}
+}
+
+func TestFaultyRepositoryPartialResults(t *testing.T) {
+	repo := NewFaultyRepository(&faultInjector{}, 1, map[string]Fault{
+		AllMethods: {PartialRate: 1},
+	})
+	for range 10 {
+		authors, err := repo.ListAuthors(context.Background())
+		if err != nil {
+			t.Fatalf("ListAuthors failed: %v", err)
+		}
+		if len(authors) >= 3 {
+			t.Fatalf("ListAuthors returned %d authors, want fewer than 3", len(authors))
+		}
+	}
+	if author, err := repo.GetAuthor(context.Background(), 1); err != nil || author.ID != 1 {
+		t.Errorf("GetAuthor = %+v, %v, want author 1", author, err)
+	}
+	if stats := repo.Stats(); stats.Partials != 10 {
+		t.Errorf("partials = %d, want 10", stats.Partials)
+	}
+}
+
+func TestResilientRepositoryRetriesInjectedFaults(t *testing.T) {
+	serialization, _ := InjectedError(ErrorKindSerialization)
+	faulty := NewFaultyRepository(&faultInjector{}, 1, map[string]Fault{
+		AllMethods: {ErrorRate: 0.3, Err: serialization},
+	})
+	repo := NewResilientRepository(faulty, ResilienceConfig{
+		MaxAttempts:      10,
+		InitialBackoff:   time.Microsecond,
+		MaxBackoff:       time.Microsecond,
+		FailureThreshold: 10,
+	})
+	for i := range 100 {
+		if err := createAuthor(repo); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if stats := faulty.Stats(); stats.Errors == 0 {
+		t.Errorf("no faults were injected")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GORMRepository struct {
@@ -46,14 +47,13 @@ func (r *GORMRepository) DeleteAuthor(ctx context.Context, id int32) error {
 }
 
 func (r *GORMRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
-	author := sqlcgen.Author{
-		ID:          id,
-		Name:        name,
-		Bio:         bio,
-		Email:       email,
-		DateOfBirth: dateOfBirth,
-	}
-	result := r.db.WithContext(ctx).Model(&author).Updates(author)
+	// Updates with a struct skips zero fields, so a map sets NULLs too
+	result := r.db.WithContext(ctx).Model(&sqlcgen.Author{ID: id}).Updates(map[string]any{
+		"name":          name,
+		"bio":           bio,
+		"email":         email,
+		"date_of_birth": dateOfBirth,
+	})
 	return result.Error
 }
 
@@ -65,3 +65,97 @@ func (r *GORMRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDa
 		Find(&authors)
 	return authors, result.Error
 }
+
+// SearchAuthors matches the generated search column, which sqlcgen.Author
+// does not map, so the predicate and ranking are built from clause expressions.
+// Like the sqlc query it selects the mapped columns only, without the tsvector.
+func (r *GORMRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	var authors []sqlcgen.Author
+	tsQuery := clause.Expr{SQL: "websearch_to_tsquery('english', ?)", Vars: []interface{}{query}}
+	result := r.db.WithContext(ctx).
+		Select("id", "name", "bio", "email", "date_of_birth").
+		Where("search @@ ?", tsQuery).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(search, ?) DESC, id",
+			Vars:               []interface{}{tsQuery},
+			WithoutParentheses: true,
+		}}).
+		Limit(int(limit)).
+		Find(&authors)
+	return authors, result.Error
+}
+
+// FilterAuthors builds the query from scopes, adding only the predicates the
+// filter sets.
+func (r *GORMRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	sortField, sortDesc, err := filter.sort()
+	if err != nil {
+		return nil, err
+	}
+	var authors []sqlcgen.Author
+	result := r.db.WithContext(ctx).
+		Scopes(
+			namePrefixScope(filter.NamePrefix),
+			emailDomainScope(filter.EmailDomain),
+			hasBioScope(filter.HasBio),
+			bornBetweenScope(filter.BornAfter, filter.BornBefore),
+			sortScope(sortField, sortDesc),
+		).
+		Find(&authors)
+	return authors, result.Error
+}
+
+func namePrefixScope(prefix string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if prefix == "" {
+			return db
+		}
+		return db.Where("starts_with(name, ?)", prefix)
+	}
+}
+
+func emailDomainScope(domain string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if domain == "" {
+			return db
+		}
+		return db.Where("split_part(email, '@', 2) = ?", domain)
+	}
+}
+
+func hasBioScope(hasBio *bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch {
+		case hasBio == nil:
+			return db
+		case *hasBio:
+			return db.Where("coalesce(bio, '') <> ''")
+		default:
+			return db.Where("coalesce(bio, '') = ''")
+		}
+	}
+}
+
+// bornBetweenScope compares dates rather than timestamps, so both bounds
+// include the whole day.
+func bornBetweenScope(after, before time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !after.IsZero() {
+			db = db.Where("date_of_birth >= CAST(? AS date)", after)
+		}
+		if !before.IsZero() {
+			db = db.Where("date_of_birth <= CAST(? AS date)", before)
+		}
+		return db
+	}
+}
+
+func sortScope(field AuthorSortField, desc bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := []clause.OrderByColumn{{Column: clause.Column{Name: string(field)}, Desc: desc}}
+		if field != SortByID {
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: string(SortByID)}, Desc: desc})
+		}
+		return db.Order(clause.OrderBy{Columns: columns})
+	}
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"gorm.io/gorm"
+)
+
+// gormBook maps the books table with its associations: the publisher it
+// belongs to and its authors through the book_authors join table.
+type gormBook struct {
+	sqlcgen.Book
+	Publisher sqlcgen.Publisher `gorm:"foreignKey:PublisherID"`
+	Authors   []sqlcgen.Author  `gorm:"many2many:book_authors;joinForeignKey:BookID;joinReferences:AuthorID"`
+}
+
+func (gormBook) TableName() string {
+	return "books"
+}
+
+// gormAuthor maps the authors table with the books it is linked to through
+// the book_authors join table.
+type gormAuthor struct {
+	sqlcgen.Author
+	Books []sqlcgen.Book `gorm:"many2many:book_authors;joinForeignKey:AuthorID;joinReferences:BookID"`
+}
+
+func (gormAuthor) TableName() string {
+	return "authors"
+}
+
+// gormAuthorBookRow is one row of the authors, book_authors and books JOIN.
+type gormAuthorBookRow struct {
+	Author sqlcgen.Author `gorm:"embedded;embeddedPrefix:author_"`
+	Book   sqlcgen.Book   `gorm:"embedded;embeddedPrefix:book_"`
+}
+
+type GORMBookRepository struct {
+	db *gorm.DB
+}
+
+func NewGORMBookRepository(db *gorm.DB) *GORMBookRepository {
+	return &GORMBookRepository{db: db}
+}
+
+func (r *GORMBookRepository) CreatePublisher(ctx context.Context, name string, country sql.NullString) (int32, error) {
+	publisher := sqlcgen.Publisher{
+		Name:    name,
+		Country: country,
+	}
+	result := r.db.WithContext(ctx).Create(&publisher)
+	return publisher.ID, result.Error
+}
+
+func (r *GORMBookRepository) GetPublisher(ctx context.Context, id int32) (sqlcgen.Publisher, error) {
+	var publisher sqlcgen.Publisher
+	result := r.db.WithContext(ctx).First(&publisher, id)
+	return publisher, result.Error
+}
+
+func (r *GORMBookRepository) DeletePublisher(ctx context.Context, id int32) error {
+	result := r.db.WithContext(ctx).Delete(&sqlcgen.Publisher{}, id)
+	return result.Error
+}
+
+// CreateBook lets GORM insert the book and its book_authors rows in one
+// transaction. The authors already exist, so upserting them is skipped.
+func (r *GORMBookRepository) CreateBook(ctx context.Context, publisherID int32, title, isbn string, publishedOn sql.NullTime, authorIDs []int32) (int32, error) {
+	book := gormBook{
+		Book: sqlcgen.Book{
+			PublisherID: publisherID,
+			Title:       title,
+			Isbn:        isbn,
+			PublishedOn: publishedOn,
+		},
+	}
+	for _, authorID := range authorIDs {
+		book.Authors = append(book.Authors, sqlcgen.Author{ID: authorID})
+	}
+	result := r.db.WithContext(ctx).Omit("Publisher", "Authors.*").Create(&book)
+	return book.ID, result.Error
+}
+
+func (r *GORMBookRepository) GetBook(ctx context.Context, id int32) (BookDetails, error) {
+	var book gormBook
+	result := r.db.WithContext(ctx).
+		Preload("Publisher").
+		Preload("Authors", func(db *gorm.DB) *gorm.DB { return db.Order("authors.name") }).
+		First(&book, id)
+	if result.Error != nil {
+		return BookDetails{}, result.Error
+	}
+	if book.Authors == nil {
+		book.Authors = []sqlcgen.Author{}
+	}
+	return BookDetails{Book: book.Book, Publisher: book.Publisher, Authors: book.Authors}, nil
+}
+
+func (r *GORMBookRepository) ListBooksByAuthor(ctx context.Context, authorID int32) ([]sqlcgen.Book, error) {
+	var books []sqlcgen.Book
+	result := r.db.WithContext(ctx).
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ?", authorID).
+		Order("books.title").
+		Find(&books)
+	return books, result.Error
+}
+
+func (r *GORMBookRepository) ListBooksByPublisher(ctx context.Context, publisherID int32) ([]sqlcgen.Book, error) {
+	var books []sqlcgen.Book
+	result := r.db.WithContext(ctx).
+		Where("publisher_id = ?", publisherID).
+		Order("title").
+		Find(&books)
+	return books, result.Error
+}
+
+func (r *GORMBookRepository) DeleteBook(ctx context.Context, id int32) error {
+	result := r.db.WithContext(ctx).Delete(&sqlcgen.Book{}, id)
+	return result.Error
+}
+
+// ListAuthorsWithBooks loads the authors and lets Preload fetch their books,
+// which takes one query for the authors, one for book_authors and one for
+// the books. Authors without books are dropped, as the sqlc JOIN does.
+func (r *GORMBookRepository) ListAuthorsWithBooks(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error) {
+	var authors []gormAuthor
+	result := r.db.WithContext(ctx).
+		Preload("Books", func(db *gorm.DB) *gorm.DB { return db.Order("books.title") }).
+		Where("id IN ?", authorIDs).
+		Order("name, id").
+		Find(&authors)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	withBooks := make([]AuthorWithBooks, 0, len(authors))
+	for _, author := range authors {
+		if len(author.Books) == 0 {
+			continue
+		}
+		withBooks = append(withBooks, AuthorWithBooks{Author: author.Author, Books: author.Books})
+	}
+	return withBooks, nil
+}
+
+// ListAuthorsWithBooksNaive loads the authors with one query and then the
+// books of each author with one query per author, the N+1 pattern.
+func (r *GORMBookRepository) ListAuthorsWithBooksNaive(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error) {
+	var authors []sqlcgen.Author
+	result := r.db.WithContext(ctx).Where("id IN ?", authorIDs).Order("name").Find(&authors)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	withBooks := make([]AuthorWithBooks, 0, len(authors))
+	for _, author := range authors {
+		books, err := r.ListBooksByAuthor(ctx, author.ID)
+		if err != nil {
+			return nil, err
+		}
+		if books == nil {
+			books = []sqlcgen.Book{}
+		}
+		withBooks = append(withBooks, AuthorWithBooks{Author: author, Books: books})
+	}
+	return withBooks, nil
+}
+
+// ListAuthorsWithBooksJoins loads the authors and their books with a single
+// query built from Joins. GORM only turns has-one and belongs-to
+// associations into JOINs, so the many-to-many JOIN is spelled out and its
+// columns are aliased for the embedded prefixes of gormAuthorBookRow. Like
+// the sqlc JOIN, authors without books are not returned.
+func (r *GORMBookRepository) ListAuthorsWithBooksJoins(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error) {
+	var rows []gormAuthorBookRow
+	result := r.db.WithContext(ctx).
+		Model(&sqlcgen.Author{}).
+		Select("authors.id AS author_id, authors.name AS author_name, authors.bio AS author_bio, "+
+			"authors.email AS author_email, authors.date_of_birth AS author_date_of_birth, "+
+			"books.id AS book_id, books.publisher_id AS book_publisher_id, books.title AS book_title, "+
+			"books.isbn AS book_isbn, books.published_on AS book_published_on").
+		Joins("JOIN book_authors ON book_authors.author_id = authors.id").
+		Joins("JOIN books ON books.id = book_authors.book_id").
+		Where("authors.id IN ?", authorIDs).
+		Order("authors.name, authors.id, books.title").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	authors := []AuthorWithBooks{}
+	for _, row := range rows {
+		authors = appendAuthorBook(authors, row.Author, row.Book)
+	}
+	return authors, nil
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// LoggingConfig controls which calls a LoggingRepository logs and at which level.
+type LoggingConfig struct {
+	// Level is the level of successful calls faster than their slow
+	// threshold. Slow calls are logged at warn and failed calls at error.
+	Level slog.Level
+	// SlowThreshold logs successful calls taking at least this long at warn;
+	// zero disables it.
+	SlowThreshold time.Duration
+	// SlowThresholds overrides SlowThreshold per method, keyed by method name
+	// such as "ListAuthors".
+	SlowThresholds map[string]time.Duration
+}
+
+// LoggingRepository logs every call to the wrapped repository: the method,
+// its arguments with emails redacted, how long it took, the rows it returned
+// and its error. Wrap it in a ContextRepository so that its lines carry the
+// request ID of the call, like those logged further down.
+type LoggingRepository struct {
+	next   AuthorRepository
+	logger *slog.Logger
+	config LoggingConfig
+}
+
+// NewLoggingRepository wraps next in a LoggingRepository writing to logger.
+func NewLoggingRepository(next AuthorRepository, logger *slog.Logger, config LoggingConfig) *LoggingRepository {
+	return &LoggingRepository{next: next, logger: logger, config: config}
+}
+
+func (r *LoggingRepository) slowThreshold(method string) time.Duration {
+	if threshold, ok := r.config.SlowThresholds[method]; ok {
+		return threshold
+	}
+	return r.config.SlowThreshold
+}
+
+// log logs a call to method that started at start. rows is the number of
+// rows returned, or negative for methods returning none.
+func (r *LoggingRepository) log(ctx context.Context, method string, start time.Time, rows int, err error, args ...slog.Attr) {
+	duration := time.Since(start)
+	threshold := r.slowThreshold(method)
+	slow := err == nil && threshold > 0 && duration >= threshold
+	level := r.config.Level
+	switch {
+	case err != nil:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	}
+	if !r.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		pkgs.Operation(method),
+		{Key: "args", Value: slog.GroupValue(args...)},
+		slog.Duration("duration", duration),
+	}
+	if rows >= 0 && err == nil {
+		attrs = append(attrs, slog.Int("rows", rows))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	message := "Repository call"
+	if slow {
+		message = "Slow repository call"
+	}
+	// Report the caller of the repository as the source of the line
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, log and the repository method
+	record := slog.NewRecord(time.Now(), level, message, pcs[0])
+	record.AddAttrs(attrs...)
+	_ = r.logger.Handler().Handle(ctx, record)
+}
+
+// redactEmail keeps the first character of the local part and the domain,
+// enough to tell authors apart in logs without recording their address.
+func redactEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+func authorArgs(name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("name", name),
+		slog.Int("bio_length", len(bio.String)),
+		slog.String("email", redactEmail(email)),
+	}
+	if dateOfBirth.Valid {
+		attrs = append(attrs, slog.Time("date_of_birth", dateOfBirth.Time))
+	}
+	return attrs
+}
+
+func (r *LoggingRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	start := time.Now()
+	id, err := r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+	args := authorArgs(name, bio, email, dateOfBirth)
+	if err == nil {
+		args = append(args, slog.Int("id", int(id)))
+	}
+	r.log(ctx, "CreateAuthor", start, -1, err, args...)
+	return id, err
+}
+
+func (r *LoggingRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	start := time.Now()
+	author, err := r.next.GetAuthor(ctx, id)
+	r.log(ctx, "GetAuthor", start, 1, err, slog.Int("id", int(id)))
+	return author, err
+}
+
+func (r *LoggingRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	start := time.Now()
+	authors, err := r.next.ListAuthors(ctx)
+	r.log(ctx, "ListAuthors", start, len(authors), err)
+	return authors, err
+}
+
+func (r *LoggingRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	start := time.Now()
+	err := r.next.DeleteAuthor(ctx, id)
+	r.log(ctx, "DeleteAuthor", start, -1, err, slog.Int("id", int(id)))
+	return err
+}
+
+func (r *LoggingRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	start := time.Now()
+	err := r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+	args := append([]slog.Attr{slog.Int("id", int(id))}, authorArgs(name, bio, email, dateOfBirth)...)
+	r.log(ctx, "UpdateAuthor", start, -1, err, args...)
+	return err
+}
+
+func (r *LoggingRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	start := time.Now()
+	authors, err := r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+	r.log(ctx, "GetAuthorsByBirthdateRange", start, len(authors), err,
+		slog.Time("start_date", startDate), slog.Time("end_date", endDate))
+	return authors, err
+}
+
+func (r *LoggingRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	start := time.Now()
+	authors, err := r.next.SearchAuthors(ctx, query, limit)
+	r.log(ctx, "SearchAuthors", start, len(authors), err,
+		slog.String("query", query), slog.Int("limit", int(limit)))
+	return authors, err
+}
+
+func (r *LoggingRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	start := time.Now()
+	authors, err := r.next.FilterAuthors(ctx, filter)
+	args := []slog.Attr{
+		slog.String("name_prefix", filter.NamePrefix),
+		slog.String("email_domain", filter.EmailDomain),
+	}
+	if filter.HasBio != nil {
+		args = append(args, slog.Bool("has_bio", *filter.HasBio))
+	}
+	if !filter.BornAfter.IsZero() {
+		args = append(args, slog.Time("born_after", filter.BornAfter))
+	}
+	if !filter.BornBefore.IsZero() {
+		args = append(args, slog.Time("born_before", filter.BornBefore))
+	}
+	args = append(args, slog.String("sort_by", string(filter.SortBy)), slog.String("direction", string(filter.Direction)))
+	r.log(ctx, "FilterAuthors", start, len(authors), err, args...)
+	return authors, err
+}
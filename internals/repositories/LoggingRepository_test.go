@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// captureHandler records the log records it handles at level and above.
+type captureHandler struct {
+	level   slog.Level
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *captureHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *captureHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h *captureHandler) all() []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]slog.Record(nil), h.records...)
+}
+
+// recordAttrs flattens the attributes of a record, naming those in groups
+// "group.key", and formats their values.
+func recordAttrs(record slog.Record) map[string]string {
+	attrs := map[string]string{}
+	var add func(prefix string, attr slog.Attr)
+	add = func(prefix string, attr slog.Attr) {
+		if attr.Value.Kind() == slog.KindGroup {
+			for _, a := range attr.Value.Group() {
+				add(prefix+attr.Key+".", a)
+			}
+			return
+		}
+		attrs[prefix+attr.Key] = attr.Value.String()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		add("", attr)
+		return true
+	})
+	return attrs
+}
+
+// slowRepository delays GetAuthor and ListAuthors of the repository it wraps.
+type slowRepository struct {
+	AuthorRepository
+	delay time.Duration
+}
+
+func (r *slowRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	time.Sleep(r.delay)
+	return r.AuthorRepository.GetAuthor(ctx, id)
+}
+
+func (r *slowRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	time.Sleep(r.delay)
+	return r.AuthorRepository.ListAuthors(ctx)
+}
+
+func TestLoggingRepository(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	errFailed := errors.New("connection reset")
+	dateOfBirth := sql.NullTime{Time: time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	tests := []struct {
+		name    string
+		config  LoggingConfig
+		handler slog.Level
+		fault   error
+		call    func(repo AuthorRepository) error
+		// wantLevel and wantMessage are those of the single logged record,
+		// unless wantNone is set
+		wantNone    bool
+		wantLevel   slog.Level
+		wantMessage string
+		wantAttrs   map[string]string
+		// absentAttrs must not be logged
+		absentAttrs []string
+	}{
+		{
+			name:        "successful call at the configured level",
+			config:      LoggingConfig{Level: slog.LevelDebug},
+			handler:     slog.LevelDebug,
+			call:        func(repo AuthorRepository) error { _, err := repo.GetAuthor(context.Background(), 7); return err },
+			wantLevel:   slog.LevelDebug,
+			wantMessage: "Repository call",
+			wantAttrs:   map[string]string{"operation": "GetAuthor", "args.id": "7", "rows": "1"},
+			absentAttrs: []string{"error"},
+		},
+		{
+			name:     "level below the handler's",
+			config:   LoggingConfig{Level: slog.LevelDebug},
+			handler:  slog.LevelInfo,
+			call:     func(repo AuthorRepository) error { _, err := repo.GetAuthor(context.Background(), 7); return err },
+			wantNone: true,
+		},
+		{
+			name:        "failed call",
+			config:      LoggingConfig{Level: slog.LevelDebug},
+			handler:     slog.LevelInfo,
+			fault:       errFailed,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelError,
+			wantMessage: "Repository call",
+			wantAttrs:   map[string]string{"operation": "ListAuthors", "error": "connection reset"},
+			absentAttrs: []string{"rows"},
+		},
+		{
+			name:    "create redacts the email and omits the bio",
+			config:  LoggingConfig{Level: slog.LevelInfo},
+			handler: slog.LevelInfo,
+			call: func(repo AuthorRepository) error {
+				_, err := repo.CreateAuthor(context.Background(), "Jane", sql.NullString{String: "secret bio", Valid: true}, "jane.doe@example.com", dateOfBirth)
+				return err
+			},
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+			wantAttrs: map[string]string{
+				"operation":          "CreateAuthor",
+				"args.name":          "Jane",
+				"args.email":         "j***@example.com",
+				"args.bio_length":    "10",
+				"args.date_of_birth": dateOfBirth.Time.String(),
+				"args.id":            "1",
+			},
+			absentAttrs: []string{"args.bio", "rows"},
+		},
+		{
+			name:    "update redacts the email",
+			config:  LoggingConfig{Level: slog.LevelInfo},
+			handler: slog.LevelInfo,
+			call: func(repo AuthorRepository) error {
+				return repo.UpdateAuthor(context.Background(), 3, "Jane", sql.NullString{}, "x@example.org", sql.NullTime{})
+			},
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+			wantAttrs:   map[string]string{"operation": "UpdateAuthor", "args.id": "3", "args.email": "x***@example.org", "args.bio_length": "0"},
+			absentAttrs: []string{"args.date_of_birth"},
+		},
+		{
+			name:        "slow call",
+			config:      LoggingConfig{Level: slog.LevelDebug, SlowThreshold: delay / 2},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelWarn,
+			wantMessage: "Slow repository call",
+			wantAttrs:   map[string]string{"operation": "ListAuthors", "rows": "3"},
+		},
+		{
+			name:        "fast call below the threshold",
+			config:      LoggingConfig{Level: slog.LevelInfo, SlowThreshold: time.Hour},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+		},
+		{
+			name:        "no threshold",
+			config:      LoggingConfig{Level: slog.LevelInfo},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+		},
+		{
+			name:        "failed slow call is an error",
+			config:      LoggingConfig{Level: slog.LevelInfo, SlowThreshold: delay / 2},
+			handler:     slog.LevelInfo,
+			fault:       errFailed,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelError,
+			wantMessage: "Repository call",
+		},
+		{
+			name: "per-method threshold raises the default",
+			config: LoggingConfig{Level: slog.LevelInfo, SlowThreshold: delay / 2,
+				SlowThresholds: map[string]time.Duration{"ListAuthors": time.Hour}},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+		},
+		{
+			name: "per-method threshold without a default",
+			config: LoggingConfig{Level: slog.LevelInfo,
+				SlowThresholds: map[string]time.Duration{"GetAuthor": delay / 2}},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.GetAuthor(context.Background(), 1); return err },
+			wantLevel:   slog.LevelWarn,
+			wantMessage: "Slow repository call",
+		},
+		{
+			name: "per-method threshold of another method",
+			config: LoggingConfig{Level: slog.LevelInfo,
+				SlowThresholds: map[string]time.Duration{"GetAuthor": delay / 2}},
+			handler:     slog.LevelInfo,
+			call:        func(repo AuthorRepository) error { _, err := repo.ListAuthors(context.Background()); return err },
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Repository call",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := &faultInjector{}
+			if tt.fault != nil {
+				injector.inject(tt.fault)
+			}
+			handler := &captureHandler{level: tt.handler}
+			repo := NewLoggingRepository(&slowRepository{AuthorRepository: injector, delay: delay}, slog.New(handler), tt.config)
+
+			if err := tt.call(repo); !errors.Is(err, tt.fault) {
+				t.Fatalf("call = %v, want %v", err, tt.fault)
+			}
+
+			records := handler.all()
+			if tt.wantNone {
+				if len(records) != 0 {
+					t.Errorf("logged %d records, want none", len(records))
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("logged %d records, want 1", len(records))
+			}
+			record := records[0]
+			if record.Level != tt.wantLevel || record.Message != tt.wantMessage {
+				t.Errorf("logged %q at %v, want %q at %v", record.Message, record.Level, tt.wantMessage, tt.wantLevel)
+			}
+			attrs := recordAttrs(record)
+			if _, ok := attrs["duration"]; !ok {
+				t.Error("duration not logged")
+			}
+			for key, want := range tt.wantAttrs {
+				if got, ok := attrs[key]; !ok || got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.absentAttrs {
+				if got, ok := attrs[key]; ok {
+					t.Errorf("%s = %q, want it not logged", key, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"jane@example.com", "j***@example.com"},
+		{"a@b", "a***@b"},
+		{"quoted@local@example.com", "q***@example.com"},
+		{"@example.com", "***"},
+		{"not an email", "***"},
+		{"", "***"},
+	}
+	for _, tt := range tests {
+		if got := redactEmail(tt.email); got != tt.want {
+			t.Errorf("redactEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
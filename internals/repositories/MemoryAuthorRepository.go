@@ -0,0 +1,329 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// MemoryRepository is an AuthorRepository keeping authors in memory, so that
+// code using an AuthorRepository can be unit tested without a database. It is
+// safe for concurrent use and follows the semantics of the PostgreSQL
+// implementations, as checked by repositorytest.TestAuthorRepository:
+//
+//   - IDs are assigned from 1 upwards and never reused.
+//   - Emails are unique; a duplicate fails with the unique_violation error
+//     PostgreSQL returns, which ErrorKind classifies as a constraint error.
+//   - GetAuthor fails with sql.ErrNoRows for a missing author, while
+//     UpdateAuthor and DeleteAuthor of a missing author do nothing.
+//   - Dates of birth are stored as dates, as the DATE column does in a
+//     session whose time zone is UTC.
+//   - ListAuthors orders by name, GetAuthorsByBirthdateRange selects dates
+//     of birth within both bounds and FilterAuthors follows AuthorFilter.
+//
+// Text is compared byte-wise where PostgreSQL uses the database collation, and
+// SearchAuthors approximates full-text search: every word of the query must
+// appear in the name or bio, except words prefixed with '-', which must not.
+// Words are matched case-insensitively but not stemmed, and matches in the
+// name rank above matches in the bio.
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	authors map[int32]sqlcgen.Author
+	lastID  int32
+}
+
+// NewMemoryRepository returns an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{authors: map[int32]sqlcgen.Author{}}
+}
+
+// emailUniqueViolation returns the error PostgreSQL reports when email is
+// already taken.
+func emailUniqueViolation(email string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "authors_email_key"`,
+		Detail:         fmt.Sprintf("Key (email)=(%s) already exists.", email),
+		TableName:      "authors",
+		ConstraintName: "authors_email_key",
+	}
+}
+
+// emailTaken reports whether an author other than id has email. The caller
+// must hold the lock.
+func (r *MemoryRepository) emailTaken(email string, id int32) bool {
+	for _, author := range r.authors {
+		if author.Email == email && author.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// toDate truncates t to midnight UTC of its date in UTC.
+func toDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func toNullDate(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: toDate(t.Time), Valid: true}
+}
+
+// selectAuthors returns the authors matching match, sorted by compare.
+func (r *MemoryRepository) selectAuthors(match func(sqlcgen.Author) bool, compare func(a, b sqlcgen.Author) int) []sqlcgen.Author {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	authors := make([]sqlcgen.Author, 0, len(r.authors))
+	for _, author := range r.authors {
+		if match(author) {
+			authors = append(authors, author)
+		}
+	}
+	slices.SortFunc(authors, compare)
+	return authors
+}
+
+func (r *MemoryRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.emailTaken(email, 0) {
+		return 0, emailUniqueViolation(email)
+	}
+	r.lastID++
+	r.authors[r.lastID] = sqlcgen.Author{
+		ID:          r.lastID,
+		Name:        name,
+		Bio:         bio,
+		Email:       email,
+		DateOfBirth: toNullDate(dateOfBirth),
+	}
+	return r.lastID, nil
+}
+
+func (r *MemoryRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return sqlcgen.Author{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	author, ok := r.authors[id]
+	if !ok {
+		return sqlcgen.Author{}, sql.ErrNoRows
+	}
+	return author, nil
+}
+
+func (r *MemoryRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	all := func(sqlcgen.Author) bool { return true }
+	return r.selectAuthors(all, func(a, b sqlcgen.Author) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	}), nil
+}
+
+func (r *MemoryRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.authors, id)
+	return nil
+}
+
+func (r *MemoryRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.authors[id]; !ok {
+		return nil
+	}
+	if r.emailTaken(email, id) {
+		return emailUniqueViolation(email)
+	}
+	r.authors[id] = sqlcgen.Author{
+		ID:          id,
+		Name:        name,
+		Bio:         bio,
+		Email:       email,
+		DateOfBirth: toNullDate(dateOfBirth),
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Both bounds compare dates, so they include the whole day
+	startDate, endDate = toDate(startDate), toDate(endDate)
+	inRange := func(a sqlcgen.Author) bool {
+		return a.DateOfBirth.Valid && !a.DateOfBirth.Time.Before(startDate) && !a.DateOfBirth.Time.After(endDate)
+	}
+	return r.selectAuthors(inRange, func(a, b sqlcgen.Author) int {
+		return cmp.Or(a.DateOfBirth.Time.Compare(b.DateOfBirth.Time), cmp.Compare(a.ID, b.ID))
+	}), nil
+}
+
+func (r *MemoryRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, errors.New("search limit must not be negative")
+	}
+
+	var include, exclude []string
+	for _, field := range strings.Fields(query) {
+		if excluded, ok := strings.CutPrefix(field, "-"); ok {
+			exclude = append(exclude, searchWords(excluded)...)
+		} else {
+			include = append(include, searchWords(field)...)
+		}
+	}
+	if len(include) == 0 {
+		return []sqlcgen.Author{}, nil
+	}
+
+	// Weighted like the search column: 1 per word in the name, 0.4 in the bio
+	ranks := map[int32]float64{}
+	matches := func(a sqlcgen.Author) bool {
+		nameWords, bioWords := searchWords(a.Name), searchWords(a.Bio.String)
+		for _, word := range exclude {
+			if slices.Contains(nameWords, word) || slices.Contains(bioWords, word) {
+				return false
+			}
+		}
+		var rank float64
+		for _, word := range include {
+			switch {
+			case slices.Contains(nameWords, word):
+				rank += 1
+			case slices.Contains(bioWords, word):
+				rank += 0.4
+			default:
+				return false
+			}
+		}
+		ranks[a.ID] = rank
+		return true
+	}
+	authors := r.selectAuthors(matches, func(a, b sqlcgen.Author) int {
+		return cmp.Or(cmp.Compare(ranks[b.ID], ranks[a.ID]), cmp.Compare(a.ID, b.ID))
+	})
+	if len(authors) > int(limit) {
+		authors = authors[:limit]
+	}
+	return authors, nil
+}
+
+// searchWords splits text into lower-case words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (r *MemoryRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	sortField, sortDesc, err := filter.sort()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Both bounds compare dates, so they include the whole day
+	var bornAfter, bornBefore time.Time
+	if !filter.BornAfter.IsZero() {
+		bornAfter = toDate(filter.BornAfter)
+	}
+	if !filter.BornBefore.IsZero() {
+		bornBefore = toDate(filter.BornBefore)
+	}
+	matches := func(a sqlcgen.Author) bool {
+		if !strings.HasPrefix(a.Name, filter.NamePrefix) {
+			return false
+		}
+		if filter.EmailDomain != "" && emailDomain(a.Email) != filter.EmailDomain {
+			return false
+		}
+		if filter.HasBio != nil && (a.Bio.String != "") != *filter.HasBio {
+			return false
+		}
+		if !bornAfter.IsZero() && (!a.DateOfBirth.Valid || a.DateOfBirth.Time.Before(bornAfter)) {
+			return false
+		}
+		if !bornBefore.IsZero() && (!a.DateOfBirth.Valid || a.DateOfBirth.Time.After(bornBefore)) {
+			return false
+		}
+		return true
+	}
+	return r.selectAuthors(matches, func(a, b sqlcgen.Author) int {
+		c := cmp.Or(compareAuthorField(a, b, sortField), cmp.Compare(a.ID, b.ID))
+		if sortDesc {
+			return -c
+		}
+		return c
+	}), nil
+}
+
+// emailDomain returns the part of email after the first @ and up to the
+// next one, as split_part(email, '@', 2) does.
+func emailDomain(email string) string {
+	parts := strings.SplitN(email, "@", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// compareAuthorField compares a and b by field in ascending order, where
+// PostgreSQL sorts NULL dates of birth last.
+func compareAuthorField(a, b sqlcgen.Author, field AuthorSortField) int {
+	switch field {
+	case SortByName:
+		return strings.Compare(a.Name, b.Name)
+	case SortByEmail:
+		return strings.Compare(a.Email, b.Email)
+	case SortByDateOfBirth:
+		switch {
+		case !a.DateOfBirth.Valid || !b.DateOfBirth.Valid:
+			// Valid sorts before NULL
+			return cmp.Compare(boolToInt(!a.DateOfBirth.Valid), boolToInt(!b.DateOfBirth.Valid))
+		default:
+			return a.DateOfBirth.Time.Compare(b.DateOfBirth.Time)
+		}
+	default:
+		return 0
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
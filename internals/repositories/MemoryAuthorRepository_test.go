@@ -0,0 +1,67 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories/repositorytest"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repositorytest.TestAuthorRepository(t, repositories.NewMemoryRepository())
+}
+
+func TestMemoryRepositoryConcurrentWrites(t *testing.T) {
+	repo := repositories.NewMemoryRepository()
+	ctx := context.Background()
+
+	// Every writer races for the same emails, so each is created once
+	const writers, emails = 8, 50
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range emails {
+				email := fmt.Sprintf("author%d@example.com", i)
+				if _, err := repo.CreateAuthor(ctx, "Author", sql.NullString{}, email, sql.NullTime{}); err != nil &&
+					repositories.ErrorKind(err) != repositories.ErrorKindConstraint {
+					t.Errorf("CreateAuthor failed: %v", err)
+				}
+				if _, err := repo.ListAuthors(ctx); err != nil {
+					t.Errorf("ListAuthors failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	authors, err := repo.ListAuthors(ctx)
+	if err != nil {
+		t.Fatalf("ListAuthors failed: %v", err)
+	}
+	if len(authors) != emails {
+		t.Errorf("created %d authors, want %d", len(authors), emails)
+	}
+}
+
+func TestMemoryRepositoryReturnsCopies(t *testing.T) {
+	repo := repositories.NewMemoryRepository()
+	ctx := context.Background()
+	id, err := repo.CreateAuthor(ctx, "Ann", sql.NullString{}, "ann@example.com", sql.NullTime{})
+	if err != nil {
+		t.Fatalf("CreateAuthor failed: %v", err)
+	}
+	authors, err := repo.ListAuthors(ctx)
+	if err != nil {
+		t.Fatalf("ListAuthors failed: %v", err)
+	}
+	authors[0].Name = "Changed"
+	if author, err := repo.GetAuthor(ctx, id); err != nil || author.Name != "Ann" {
+		t.Errorf("GetAuthor = %+v, %v after changing a listed author, want Ann", author, err)
+	}
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RepositoryMetrics holds the Prometheus collectors shared by the
+// MetricsRepository of each implementation. Every series is labeled with the
+// implementation and the method called.
+type RepositoryMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewRepositoryMetrics creates the repository collectors and registers them
+// with registerer.
+func NewRepositoryMetrics(registerer prometheus.Registerer) (*RepositoryMetrics, error) {
+	m := &RepositoryMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "sqlcvsgorm",
+			Subsystem: "repository",
+			Name:      "call_duration_seconds",
+			Help:      "Duration of AuthorRepository calls.",
+			// 100µs to about 3s
+			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, []string{"implementation", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sqlcvsgorm",
+			Subsystem: "repository",
+			Name:      "call_errors_total",
+			Help:      "AuthorRepository calls that returned an error, by kind of error.",
+		}, []string{"implementation", "method", "kind"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "sqlcvsgorm",
+			Subsystem: "repository",
+			Name:      "calls_in_flight",
+			Help:      "AuthorRepository calls currently running.",
+		}, []string{"implementation", "method"}),
+	}
+	for _, collector := range []prometheus.Collector{m.duration, m.errors, m.inFlight} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// MetricsRepository records the latency, errors and concurrency of every
+// call to the wrapped repository in a RepositoryMetrics.
+type MetricsRepository struct {
+	next           AuthorRepository
+	implementation string
+	metrics        *RepositoryMetrics
+}
+
+// NewMetricsRepository wraps next in a MetricsRepository labeling its series
+// with implementation, such as "sqlc" or "gorm".
+func NewMetricsRepository(next AuthorRepository, implementation string, metrics *RepositoryMetrics) *MetricsRepository {
+	return &MetricsRepository{next: next, implementation: implementation, metrics: metrics}
+}
+
+// observe records the start of a call to method and returns the function
+// recording its end.
+func (r *MetricsRepository) observe(method string) func(error) {
+	inFlight := r.metrics.inFlight.WithLabelValues(r.implementation, method)
+	inFlight.Inc()
+	start := time.Now()
+	return func(err error) {
+		r.metrics.duration.WithLabelValues(r.implementation, method).Observe(time.Since(start).Seconds())
+		inFlight.Dec()
+		if err != nil {
+			r.metrics.errors.WithLabelValues(r.implementation, method, ErrorKind(err)).Inc()
+		}
+	}
+}
+
+func (r *MetricsRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	done := r.observe("CreateAuthor")
+	id, err := r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+	done(err)
+	return id, err
+}
+
+func (r *MetricsRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	done := r.observe("GetAuthor")
+	author, err := r.next.GetAuthor(ctx, id)
+	done(err)
+	return author, err
+}
+
+func (r *MetricsRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	done := r.observe("ListAuthors")
+	authors, err := r.next.ListAuthors(ctx)
+	done(err)
+	return authors, err
+}
+
+func (r *MetricsRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	done := r.observe("DeleteAuthor")
+	err := r.next.DeleteAuthor(ctx, id)
+	done(err)
+	return err
+}
+
+func (r *MetricsRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	done := r.observe("UpdateAuthor")
+	err := r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+	done(err)
+	return err
+}
+
+func (r *MetricsRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	done := r.observe("GetAuthorsByBirthdateRange")
+	authors, err := r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+	done(err)
+	return authors, err
+}
+
+func (r *MetricsRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	done := r.observe("SearchAuthors")
+	authors, err := r.next.SearchAuthors(ctx, query, limit)
+	done(err)
+	return authors, err
+}
+
+func (r *MetricsRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	done := r.observe("FilterAuthors")
+	authors, err := r.next.FilterAuthors(ctx, filter)
+	done(err)
+	return authors, err
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gateRepository holds GetAuthor of the repository it wraps until
+// release is closed, after signalling on entered.
+type gateRepository struct {
+	AuthorRepository
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *gateRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	r.entered <- struct{}{}
+	<-r.release
+	return r.AuthorRepository.GetAuthor(ctx, id)
+}
+
+func TestMetricsRepository(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	metrics, err := NewRepositoryMetrics(registry)
+	if err != nil {
+		t.Fatalf("NewRepositoryMetrics failed: %v", err)
+	}
+	sqlcInjector, gormInjector := &faultInjector{}, &faultInjector{}
+	sqlcRepo := NewMetricsRepository(sqlcInjector, "sqlc", metrics)
+	gormRepo := NewMetricsRepository(gormInjector, "gorm", metrics)
+	ctx := context.Background()
+
+	sqlcRepo.GetAuthor(ctx, 1)
+	sqlcRepo.GetAuthor(ctx, 2)
+	sqlcInjector.inject(errUniqueEmail, errDeadlock)
+	sqlcRepo.CreateAuthor(ctx, "Ann", sql.NullString{}, "ann@example.com", sql.NullTime{})
+	sqlcRepo.ListAuthors(ctx)
+	gormInjector.inject(errUniqueEmail)
+	gormRepo.CreateAuthor(ctx, "Ann", sql.NullString{}, "ann@example.com", sql.NullTime{})
+	gormRepo.DeleteAuthor(ctx, 1)
+
+	// Both implementations report a unique violation under the same kind,
+	// although sqlc's comes from lib/pq
+	wantErrors := `
+# HELP sqlcvsgorm_repository_call_errors_total AuthorRepository calls that returned an error, by kind of error.
+# TYPE sqlcvsgorm_repository_call_errors_total counter
+sqlcvsgorm_repository_call_errors_total{implementation="gorm",kind="constraint",method="CreateAuthor"} 1
+sqlcvsgorm_repository_call_errors_total{implementation="sqlc",kind="constraint",method="CreateAuthor"} 1
+sqlcvsgorm_repository_call_errors_total{implementation="sqlc",kind="serialization",method="ListAuthors"} 1
+`
+	if err := testutil.CollectAndCompare(metrics.errors, strings.NewReader(wantErrors)); err != nil {
+		t.Error(err)
+	}
+
+	// Failed calls are timed as well
+	calls := []struct {
+		implementation, method string
+		want                   uint64
+	}{
+		{"sqlc", "GetAuthor", 2},
+		{"sqlc", "CreateAuthor", 1},
+		{"sqlc", "ListAuthors", 1},
+		{"gorm", "CreateAuthor", 1},
+		{"gorm", "DeleteAuthor", 1},
+	}
+	if got := testutil.CollectAndCount(metrics.duration); got != len(calls) {
+		t.Errorf("%d duration series, want %d", got, len(calls))
+	}
+	for _, c := range calls {
+		histogram := metrics.duration.WithLabelValues(c.implementation, c.method).(prometheus.Histogram)
+		if got := sampleCount(t, histogram); got != c.want {
+			t.Errorf("%s %s observed %d times, want %d", c.implementation, c.method, got, c.want)
+		}
+	}
+
+	// Every call has finished
+	for _, c := range calls {
+		if got := testutil.ToFloat64(metrics.inFlight.WithLabelValues(c.implementation, c.method)); got != 0 {
+			t.Errorf("%s %s in flight = %v, want 0", c.implementation, c.method, got)
+		}
+	}
+
+	if problems, err := testutil.GatherAndLint(registry); err != nil || len(problems) > 0 {
+		t.Errorf("lint = %v, %v", problems, err)
+	}
+}
+
+func TestMetricsRepositoryInFlight(t *testing.T) {
+	metrics, err := NewRepositoryMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewRepositoryMetrics failed: %v", err)
+	}
+	blocking := &gateRepository{AuthorRepository: &faultInjector{}, entered: make(chan struct{}), release: make(chan struct{})}
+	repo := NewMetricsRepository(blocking, "gorm", metrics)
+	inFlight := metrics.inFlight.WithLabelValues("gorm", "GetAuthor")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.GetAuthor(context.Background(), 1)
+	}()
+	<-blocking.entered
+	if got := testutil.ToFloat64(inFlight); got != 1 {
+		t.Errorf("in flight during the call = %v, want 1", got)
+	}
+	close(blocking.release)
+	<-done
+	if got := testutil.ToFloat64(inFlight); got != 0 {
+		t.Errorf("in flight after the call = %v, want 0", got)
+	}
+}
+
+func TestNewRepositoryMetricsRegistersOnce(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := NewRepositoryMetrics(registry); err != nil {
+		t.Fatalf("NewRepositoryMetrics failed: %v", err)
+	}
+	if _, err := NewRepositoryMetrics(registry); err == nil {
+		t.Error("registering the metrics twice succeeded")
+	}
+}
+
+func sampleCount(t *testing.T, histogram prometheus.Histogram) uint64 {
+	t.Helper()
+	metric := make(chan prometheus.Metric, 1)
+	histogram.Collect(metric)
+	var m dto.Metric
+	if err := (<-metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
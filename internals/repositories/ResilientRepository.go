@@ -0,0 +1,292 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// ErrCircuitOpen is returned by a ResilientRepository while its circuit
+// breaker rejects calls.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// IsRetryable reports whether err is transient: a serialization failure or
+// deadlock (SQLSTATE 40001 or 40P01), or a broken or refused connection.
+func IsRetryable(err error) bool {
+	switch ErrorKind(err) {
+	case ErrorKindSerialization, ErrorKindConnection:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSerializationFailure reports whether err guarantees that the failed
+// statement was rolled back.
+func isSerializationFailure(err error) bool {
+	return ErrorKind(err) == ErrorKindSerialization
+}
+
+// ResilienceConfig controls the retries and the circuit breaker of a
+// ResilientRepository. Zero values select the defaults.
+type ResilienceConfig struct {
+	// MaxAttempts is how many times a call is tried, including the first
+	// time; it defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles with
+	// every retry up to MaxBackoff, and each wait is randomized between
+	// half and all of it. They default to 10ms and 1s.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// FailureThreshold is how many attempts in a row must fail with a
+	// retryable error for the circuit breaker to open; it defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit breaker rejects calls once open,
+	// before letting one through to probe the database; it defaults to 10s.
+	OpenTimeout time.Duration
+}
+
+func (c ResilienceConfig) withDefaults() ResilienceConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = 10 * time.Millisecond
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Second
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = c.InitialBackoff
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = 10 * time.Second
+	}
+	return c
+}
+
+// ResilientRepository retries the calls to the wrapped repository that fail
+// with a transient error, waiting with exponential backoff and jitter in
+// between, and stops calling it while a circuit breaker is open.
+//
+// Reads, updates and deletes are retried on every error IsRetryable accepts.
+// CreateAuthor is not idempotent: after a lost connection the author may
+// have been inserted, so it is only retried on serialization failures and
+// deadlocks, which roll the statement back.
+type ResilientRepository struct {
+	next    AuthorRepository
+	config  ResilienceConfig
+	breaker circuitBreaker
+}
+
+// NewResilientRepository wraps next in a ResilientRepository.
+func NewResilientRepository(next AuthorRepository, config ResilienceConfig) *ResilientRepository {
+	config = config.withDefaults()
+	return &ResilientRepository{
+		next:    next,
+		config:  config,
+		breaker: circuitBreaker{threshold: config.FailureThreshold, openTimeout: config.OpenTimeout},
+	}
+}
+
+// CircuitState returns the current state of the circuit breaker.
+func (r *ResilientRepository) CircuitState() CircuitState {
+	return r.breaker.currentState()
+}
+
+// do runs call until it succeeds, fails with an error retryable rejects, or
+// runs out of attempts, and returns its last error.
+func (r *ResilientRepository) do(ctx context.Context, retryable func(error) bool, call func(context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !r.breaker.allow() {
+			if err != nil {
+				return fmt.Errorf("%w after %d attempts: %w", ErrCircuitOpen, attempt-1, err)
+			}
+			return ErrCircuitOpen
+		}
+		err = call(ctx)
+		r.breaker.record(IsRetryable(err))
+		if err == nil || !retryable(err) || attempt >= r.config.MaxAttempts {
+			return err
+		}
+
+		if waitErr := wait(ctx, r.backoff(attempt)); waitErr != nil {
+			return fmt.Errorf("%w after %d attempts: %w", waitErr, attempt, err)
+		}
+	}
+}
+
+// backoff returns the wait after the given failed attempt: a random duration
+// between half and all of InitialBackoff doubled attempt-1 times, capped at
+// MaxBackoff.
+func (r *ResilientRepository) backoff(attempt int) time.Duration {
+	backoff := r.config.MaxBackoff
+	if shift := attempt - 1; shift < 32 && r.config.InitialBackoff<<shift < r.config.MaxBackoff {
+		backoff = r.config.InitialBackoff << shift
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+func (r *ResilientRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	var id int32
+	err := r.do(ctx, isSerializationFailure, func(ctx context.Context) error {
+		var err error
+		id, err = r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+		return err
+	})
+	return id, err
+}
+
+func (r *ResilientRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	var author sqlcgen.Author
+	err := r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		var err error
+		author, err = r.next.GetAuthor(ctx, id)
+		return err
+	})
+	return author, err
+}
+
+func (r *ResilientRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	var authors []sqlcgen.Author
+	err := r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		var err error
+		authors, err = r.next.ListAuthors(ctx)
+		return err
+	})
+	return authors, err
+}
+
+func (r *ResilientRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	return r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		return r.next.DeleteAuthor(ctx, id)
+	})
+}
+
+func (r *ResilientRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	return r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		return r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+	})
+}
+
+func (r *ResilientRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	var authors []sqlcgen.Author
+	err := r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		var err error
+		authors, err = r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+		return err
+	})
+	return authors, err
+}
+
+func (r *ResilientRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	var authors []sqlcgen.Author
+	err := r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		var err error
+		authors, err = r.next.SearchAuthors(ctx, query, limit)
+		return err
+	})
+	return authors, err
+}
+
+func (r *ResilientRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	var authors []sqlcgen.Author
+	err := r.do(ctx, IsRetryable, func(ctx context.Context) error {
+		var err error
+		authors, err = r.next.FilterAuthors(ctx, filter)
+		return err
+	})
+	return authors, err
+}
+
+// CircuitState is the state of the circuit breaker of a ResilientRepository.
+type CircuitState string
+
+const (
+	// CircuitClosed lets every call through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects every call with ErrCircuitOpen.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single call through to probe the database,
+	// closing the circuit if it succeeds and opening it again if it fails.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// circuitBreaker opens after threshold consecutive failures and stays open
+// for openTimeout. Only retryable errors count as failures: other errors
+// mean the database answered.
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == "" {
+		return CircuitClosed
+	}
+	return b.state
+}
+
+// allow reports whether a call may go through, moving an open circuit whose
+// timeout has passed to half-open.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		// The probe is still running
+		return false
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a call allowed by allow.
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.state == CircuitHalfOpen:
+		if failed {
+			b.open()
+		} else {
+			b.state, b.failures = CircuitClosed, 0
+		}
+	case b.state == CircuitOpen:
+		// A call started before the circuit opened
+	case failed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.state, b.failures, b.openedAt = CircuitOpen, 0, time.Now()
+}
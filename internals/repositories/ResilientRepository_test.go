@@ -0,0 +1,361 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+var (
+	errSerialization = &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+	errDeadlock      = &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	errUniqueEmail   = &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+)
+
+// faultInjector is an AuthorRepository whose calls fail with the queued
+// errors, one per call, and succeed once the queue is empty.
+type faultInjector struct {
+	mu     sync.Mutex
+	faults []error
+	calls  int
+}
+
+func (f *faultInjector) inject(faults ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, faults...)
+}
+
+func (f *faultInjector) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *faultInjector) call() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.faults) == 0 {
+		return nil
+	}
+	err := f.faults[0]
+	f.faults = f.faults[1:]
+	return err
+}
+
+// list returns three authors unless the call fails.
+func (f *faultInjector) list() ([]sqlcgen.Author, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return []sqlcgen.Author{{ID: 1, Name: "Ann"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Cid"}}, nil
+}
+
+func (f *faultInjector) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	if err := f.call(); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (f *faultInjector) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	if err := f.call(); err != nil {
+		return sqlcgen.Author{}, err
+	}
+	return sqlcgen.Author{ID: id, Name: "Jane"}, nil
+}
+
+func (f *faultInjector) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	return f.list()
+}
+
+func (f *faultInjector) DeleteAuthor(ctx context.Context, id int32) error {
+	return f.call()
+}
+
+func (f *faultInjector) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	return f.call()
+}
+
+func (f *faultInjector) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	return f.list()
+}
+
+func (f *faultInjector) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	return f.list()
+}
+
+func (f *faultInjector) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	return f.list()
+}
+
+// fastRetries keeps the backoff short and the circuit closed.
+var fastRetries = ResilienceConfig{
+	MaxAttempts:      3,
+	InitialBackoff:   time.Microsecond,
+	MaxBackoff:       time.Millisecond,
+	FailureThreshold: 100,
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", errSerialization, true},
+		{"deadlock", errDeadlock, true},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"bad connection", driver.ErrBadConn, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"unique violation", errUniqueEmail, false},
+		{"syntax error", &pq.Error{Code: "42601"}, false},
+		{"not found", sql.ErrNoRows, false},
+		{"canceled", context.Canceled, false},
+		{"timeout", context.DeadlineExceeded, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResilientRepositoryRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		faults    []error
+		call      func(AuthorRepository) error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "succeeds after transient failures",
+			faults:    []error{errSerialization, errDeadlock},
+			call:      getAuthor,
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max attempts",
+			faults:    []error{errSerialization, io.ErrUnexpectedEOF, errDeadlock},
+			call:      getAuthor,
+			wantErr:   errDeadlock,
+			wantCalls: 3,
+		},
+		{
+			name:      "does not retry not found",
+			faults:    []error{sql.ErrNoRows},
+			call:      getAuthor,
+			wantErr:   sql.ErrNoRows,
+			wantCalls: 1,
+		},
+		{
+			name:      "does not retry constraint violations",
+			faults:    []error{errUniqueEmail},
+			call:      updateAuthor,
+			wantErr:   errUniqueEmail,
+			wantCalls: 1,
+		},
+		{
+			name:      "retries idempotent writes on connection errors",
+			faults:    []error{driver.ErrBadConn},
+			call:      updateAuthor,
+			wantCalls: 2,
+		},
+		{
+			name:      "retries create on serialization failures",
+			faults:    []error{errSerialization},
+			call:      createAuthor,
+			wantCalls: 2,
+		},
+		{
+			name:      "does not retry create on connection errors",
+			faults:    []error{io.ErrUnexpectedEOF},
+			call:      createAuthor,
+			wantErr:   io.ErrUnexpectedEOF,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faults := &faultInjector{}
+			faults.inject(tt.faults...)
+			repo := NewResilientRepository(faults, fastRetries)
+
+			err := tt.call(repo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls := faults.callCount(); calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientRepositoryStopsOnCancel(t *testing.T) {
+	faults := &faultInjector{}
+	faults.inject(errSerialization, errSerialization)
+	config := fastRetries
+	config.InitialBackoff, config.MaxBackoff = time.Hour, time.Hour
+	repo := NewResilientRepository(faults, config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := repo.GetAuthor(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errSerialization) {
+		t.Errorf("err = %v, want the deadline and the last failure", err)
+	}
+	if calls := faults.callCount(); calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestResilientRepositoryCircuitBreaker(t *testing.T) {
+	faults := &faultInjector{}
+	repo := NewResilientRepository(faults, ResilienceConfig{
+		MaxAttempts:      2,
+		InitialBackoff:   time.Microsecond,
+		MaxBackoff:       time.Microsecond,
+		FailureThreshold: 3,
+		OpenTimeout:      20 * time.Millisecond,
+	})
+
+	// Errors from a database that answered do not count as failures
+	faults.inject(sql.ErrNoRows, sql.ErrNoRows, sql.ErrNoRows)
+	for range 3 {
+		getAuthor(repo)
+	}
+	if state := repo.CircuitState(); state != CircuitClosed {
+		t.Fatalf("state after not found errors = %s, want %s", state, CircuitClosed)
+	}
+
+	// The first call fails twice and the second reaches the threshold
+	faults.inject(driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn)
+	getAuthor(repo)
+	if err := getAuthor(repo); !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("err = %v, want %v", err, driver.ErrBadConn)
+	}
+	if state := repo.CircuitState(); state != CircuitOpen {
+		t.Fatalf("state after 3 failures = %s, want %s", state, CircuitOpen)
+	}
+
+	// An open circuit fails fast without calling the database
+	calls := faults.callCount()
+	if err := getAuthor(repo); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want %v", err, ErrCircuitOpen)
+	}
+	if faults.callCount() != calls {
+		t.Errorf("open circuit called the database")
+	}
+
+	// A failed probe opens the circuit again
+	time.Sleep(30 * time.Millisecond)
+	faults.inject(errDeadlock)
+	if err := getAuthor(repo); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, errDeadlock) {
+		t.Errorf("err = %v, want %v after %v", err, ErrCircuitOpen, errDeadlock)
+	}
+	if state := repo.CircuitState(); state != CircuitOpen {
+		t.Fatalf("state after failed probe = %s, want %s", state, CircuitOpen)
+	}
+
+	// A successful probe closes it
+	time.Sleep(30 * time.Millisecond)
+	if err := getAuthor(repo); err != nil {
+		t.Errorf("probe failed: %v", err)
+	}
+	if state := repo.CircuitState(); state != CircuitClosed {
+		t.Errorf("state after successful probe = %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestResilientRepositoryHalfOpenAllowsOneProbe(t *testing.T) {
+	faults := &blockingRepository{faultInjector: &faultInjector{}, release: make(chan struct{})}
+	repo := NewResilientRepository(faults, ResilienceConfig{
+		MaxAttempts:      1,
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+	})
+	faults.inject(driver.ErrBadConn)
+	getAuthor(repo)
+	time.Sleep(5 * time.Millisecond)
+
+	probe := make(chan error)
+	go func() { probe <- updateAuthor(repo) }()
+	for repo.CircuitState() != CircuitHalfOpen {
+		time.Sleep(time.Millisecond)
+	}
+	if err := updateAuthor(repo); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err during probe = %v, want %v", err, ErrCircuitOpen)
+	}
+	close(faults.release)
+	if err := <-probe; err != nil {
+		t.Errorf("probe failed: %v", err)
+	}
+	if state := repo.CircuitState(); state != CircuitClosed {
+		t.Errorf("state after successful probe = %s, want %s", state, CircuitClosed)
+	}
+}
+
+// blockingRepository holds UpdateAuthor until release is closed.
+type blockingRepository struct {
+	*faultInjector
+	release chan struct{}
+}
+
+func (r *blockingRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	<-r.release
+	return r.faultInjector.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+}
+
+func TestResilientRepositoryBackoff(t *testing.T) {
+	repo := NewResilientRepository(&faultInjector{}, ResilienceConfig{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	})
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 5 * time.Millisecond, 10 * time.Millisecond},
+		{2, 10 * time.Millisecond, 20 * time.Millisecond},
+		{3, 20 * time.Millisecond, 40 * time.Millisecond},
+		{4, 25 * time.Millisecond, 50 * time.Millisecond},
+		{64, 25 * time.Millisecond, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			if wait := repo.backoff(tt.attempt); wait < tt.min || wait > tt.max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, wait, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func getAuthor(repo AuthorRepository) error {
+	_, err := repo.GetAuthor(context.Background(), 1)
+	return err
+}
+
+func updateAuthor(repo AuthorRepository) error {
+	return repo.UpdateAuthor(context.Background(), 1, "Jane", sql.NullString{}, "jane@example.com", sql.NullTime{})
+}
+
+func createAuthor(repo AuthorRepository) error {
+	_, err := repo.CreateAuthor(context.Background(), "Jane", sql.NullString{}, "jane@example.com", sql.NullTime{})
+	return err
+}
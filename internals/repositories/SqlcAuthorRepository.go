@@ -56,3 +56,32 @@ func (r *SQLCRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDa
 	}
 	return r.queries.GetAuthorsByBirthdateRange(ctx, params)
 }
+
+func (r *SQLCRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	params := sqlcgen.SearchAuthorsParams{
+		Query: query,
+		Limit: limit,
+	}
+	return r.queries.SearchAuthors(ctx, params)
+}
+
+// FilterAuthors runs a single static query in which every criterion is a
+// predicate that is disabled by passing NULL.
+func (r *SQLCRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	sortField, sortDesc, err := filter.sort()
+	if err != nil {
+		return nil, err
+	}
+	params := sqlcgen.FilterAuthorsParams{
+		NamePrefix:  sql.NullString{String: filter.NamePrefix, Valid: filter.NamePrefix != ""},
+		EmailDomain: sql.NullString{String: filter.EmailDomain, Valid: filter.EmailDomain != ""},
+		BornAfter:   sql.NullTime{Time: filter.BornAfter, Valid: !filter.BornAfter.IsZero()},
+		BornBefore:  sql.NullTime{Time: filter.BornBefore, Valid: !filter.BornBefore.IsZero()},
+		SortField:   string(sortField),
+		SortDesc:    sortDesc,
+	}
+	if filter.HasBio != nil {
+		params.HasBio = sql.NullBool{Bool: *filter.HasBio, Valid: true}
+	}
+	return r.queries.FilterAuthors(ctx, params)
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+type SQLCBookRepository struct {
+	db      *sql.DB
+	queries *sqlcgen.Queries
+}
+
+// NewSQLCBookRepository needs the *sql.DB behind queries to run CreateBook
+// in a transaction.
+func NewSQLCBookRepository(db *sql.DB, queries *sqlcgen.Queries) *SQLCBookRepository {
+	return &SQLCBookRepository{db: db, queries: queries}
+}
+
+func (r *SQLCBookRepository) CreatePublisher(ctx context.Context, name string, country sql.NullString) (int32, error) {
+	params := sqlcgen.CreatePublisherParams{
+		Name:    name,
+		Country: country,
+	}
+	return r.queries.CreatePublisher(ctx, params)
+}
+
+func (r *SQLCBookRepository) GetPublisher(ctx context.Context, id int32) (sqlcgen.Publisher, error) {
+	return r.queries.GetPublisher(ctx, id)
+}
+
+func (r *SQLCBookRepository) DeletePublisher(ctx context.Context, id int32) error {
+	return r.queries.DeletePublisher(ctx, id)
+}
+
+func (r *SQLCBookRepository) CreateBook(ctx context.Context, publisherID int32, title, isbn string, publishedOn sql.NullTime, authorIDs []int32) (int32, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	id, err := queries.CreateBook(ctx, sqlcgen.CreateBookParams{
+		PublisherID: publisherID,
+		Title:       title,
+		Isbn:        isbn,
+		PublishedOn: publishedOn,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, authorID := range authorIDs {
+		if err := queries.AddBookAuthor(ctx, sqlcgen.AddBookAuthorParams{BookID: id, AuthorID: authorID}); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// GetBook loads the book and its publisher with one JOIN and the authors
+// with a second query.
+func (r *SQLCBookRepository) GetBook(ctx context.Context, id int32) (BookDetails, error) {
+	row, err := r.queries.GetBookWithPublisher(ctx, id)
+	if err != nil {
+		return BookDetails{}, err
+	}
+	authors, err := r.queries.ListAuthorsByBook(ctx, id)
+	if err != nil {
+		return BookDetails{}, err
+	}
+	return BookDetails{Book: row.Book, Publisher: row.Publisher, Authors: authors}, nil
+}
+
+func (r *SQLCBookRepository) ListBooksByAuthor(ctx context.Context, authorID int32) ([]sqlcgen.Book, error) {
+	return r.queries.ListBooksByAuthor(ctx, authorID)
+}
+
+func (r *SQLCBookRepository) ListBooksByPublisher(ctx context.Context, publisherID int32) ([]sqlcgen.Book, error) {
+	return r.queries.ListBooksByPublisher(ctx, publisherID)
+}
+
+func (r *SQLCBookRepository) DeleteBook(ctx context.Context, id int32) error {
+	return r.queries.DeleteBook(ctx, id)
+}
+
+// ListAuthorsWithBooks loads the authors and their books with a single JOIN,
+// scanned through sqlc.embed. Authors without books are not returned, since
+// sqlc.embed cannot scan the NULL columns of a LEFT JOIN.
+func (r *SQLCBookRepository) ListAuthorsWithBooks(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error) {
+	rows, err := r.queries.ListAuthorsWithBooks(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	authors := []AuthorWithBooks{}
+	for _, row := range rows {
+		authors = appendAuthorBook(authors, row.Author, row.Book)
+	}
+	return authors, nil
+}
+
+// ListAuthorsWithBooksNaive loads the authors with one query and then the
+// books of each author with one query per author, the N+1 pattern.
+func (r *SQLCBookRepository) ListAuthorsWithBooksNaive(ctx context.Context, authorIDs []int32) ([]AuthorWithBooks, error) {
+	authors, err := r.queries.ListAuthorsByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AuthorWithBooks, 0, len(authors))
+	for _, author := range authors {
+		books, err := r.queries.ListBooksByAuthor(ctx, author.ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, AuthorWithBooks{Author: author, Books: books})
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingRepository starts a span for every call to the wrapped repository.
+// The spans of the statements a call sends, recorded by the tracing package,
+// are its children.
+type TracingRepository struct {
+	next           AuthorRepository
+	implementation string
+	tracer         trace.Tracer
+}
+
+// NewTracingRepository wraps next in a TracingRepository tracing with a
+// tracer of provider. implementation, such as "sqlc" or "gorm", is set as
+// the repository.implementation attribute of every span.
+func NewTracingRepository(next AuthorRepository, implementation string, provider trace.TracerProvider) *TracingRepository {
+	return &TracingRepository{
+		next:           next,
+		implementation: implementation,
+		tracer:         provider.Tracer("github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories"),
+	}
+}
+
+func (r *TracingRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("repository.implementation", r.implementation))
+	return r.tracer.Start(ctx, "AuthorRepository."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err. rows is the number of rows returned, or
+// negative for methods returning none.
+func endSpan(span trace.Span, rows int, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.type", ErrorKind(err)))
+	} else if rows >= 0 {
+		span.SetAttributes(attribute.Int("repository.rows", rows))
+	}
+	span.End()
+}
+
+func (r *TracingRepository) CreateAuthor(ctx context.Context, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) (int32, error) {
+	ctx, span := r.start(ctx, "CreateAuthor")
+	id, err := r.next.CreateAuthor(ctx, name, bio, email, dateOfBirth)
+	if err == nil {
+		span.SetAttributes(attribute.Int("author.id", int(id)))
+	}
+	endSpan(span, -1, err)
+	return id, err
+}
+
+func (r *TracingRepository) GetAuthor(ctx context.Context, id int32) (sqlcgen.Author, error) {
+	ctx, span := r.start(ctx, "GetAuthor", attribute.Int("author.id", int(id)))
+	author, err := r.next.GetAuthor(ctx, id)
+	endSpan(span, 1, err)
+	return author, err
+}
+
+func (r *TracingRepository) ListAuthors(ctx context.Context) ([]sqlcgen.Author, error) {
+	ctx, span := r.start(ctx, "ListAuthors")
+	authors, err := r.next.ListAuthors(ctx)
+	endSpan(span, len(authors), err)
+	return authors, err
+}
+
+func (r *TracingRepository) DeleteAuthor(ctx context.Context, id int32) error {
+	ctx, span := r.start(ctx, "DeleteAuthor", attribute.Int("author.id", int(id)))
+	err := r.next.DeleteAuthor(ctx, id)
+	endSpan(span, -1, err)
+	return err
+}
+
+func (r *TracingRepository) UpdateAuthor(ctx context.Context, id int32, name string, bio sql.NullString, email string, dateOfBirth sql.NullTime) error {
+	ctx, span := r.start(ctx, "UpdateAuthor", attribute.Int("author.id", int(id)))
+	err := r.next.UpdateAuthor(ctx, id, name, bio, email, dateOfBirth)
+	endSpan(span, -1, err)
+	return err
+}
+
+func (r *TracingRepository) GetAuthorsByBirthdateRange(ctx context.Context, startDate, endDate time.Time) ([]sqlcgen.Author, error) {
+	ctx, span := r.start(ctx, "GetAuthorsByBirthdateRange")
+	authors, err := r.next.GetAuthorsByBirthdateRange(ctx, startDate, endDate)
+	endSpan(span, len(authors), err)
+	return authors, err
+}
+
+func (r *TracingRepository) SearchAuthors(ctx context.Context, query string, limit int32) ([]sqlcgen.Author, error) {
+	ctx, span := r.start(ctx, "SearchAuthors", attribute.Int("search.limit", int(limit)))
+	authors, err := r.next.SearchAuthors(ctx, query, limit)
+	endSpan(span, len(authors), err)
+	return authors, err
+}
+
+func (r *TracingRepository) FilterAuthors(ctx context.Context, filter AuthorFilter) ([]sqlcgen.Author, error) {
+	ctx, span := r.start(ctx, "FilterAuthors")
+	authors, err := r.next.FilterAuthors(ctx, filter)
+	endSpan(span, len(authors), err)
+	return authors, err
+}
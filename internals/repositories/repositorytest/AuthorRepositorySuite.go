@@ -0,0 +1,427 @@
+package repositorytest
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// TestAuthorRepository checks that repo honors the semantics every
+// AuthorRepository shares: unique emails, ordering, inclusive date bounds,
+// filtering, search ranking and not-found errors.
+//
+// The repository does not need to be empty. Every author the suite creates
+// carries a marker unique to the run, results are narrowed down to those
+// authors, and they are deleted when the test ends. Dates are expected to be
+// stored as a PostgreSQL DATE column does in a session whose time zone is
+// UTC.
+func TestAuthorRepository(t *testing.T, repo repositories.AuthorRepository) {
+	s := &suite{repo: repo, marker: fmt.Sprintf("m%010d", rand.Uint32())}
+	t.Run("CreateAndGet", s.testCreateAndGet)
+	t.Run("GetMissing", s.testGetMissing)
+	t.Run("UniqueEmail", s.testUniqueEmail)
+	t.Run("Update", s.testUpdate)
+	t.Run("Delete", s.testDelete)
+	t.Run("ListOrderedByName", s.testListOrderedByName)
+	t.Run("BirthdateRange", s.testBirthdateRange)
+	t.Run("Filter", s.testFilter)
+	t.Run("FilterSort", s.testFilterSort)
+	t.Run("Search", s.testSearch)
+	t.Run("Canceled", s.testCanceled)
+}
+
+type suite struct {
+	repo repositories.AuthorRepository
+	// marker starts the name of every author the suite creates, and is a
+	// word that full-text search indexes as is
+	marker string
+	// emails numbers the email addresses handed out
+	emails int
+}
+
+// author describes an author to create.
+type author struct {
+	name        string
+	bio         string
+	dateOfBirth string
+}
+
+// create creates a, named after the marker, and deletes it when the test ends.
+func (s *suite) create(t *testing.T, a author) sqlcgen.Author {
+	t.Helper()
+	s.emails++
+	created := sqlcgen.Author{
+		Name:        s.marker + " " + a.name,
+		Bio:         sql.NullString{String: a.bio, Valid: a.bio != ""},
+		Email:       fmt.Sprintf("author%04d@%s.test", s.emails, s.marker),
+		DateOfBirth: date(t, a.dateOfBirth),
+	}
+	id, err := s.repo.CreateAuthor(context.Background(), created.Name, created.Bio, created.Email, created.DateOfBirth)
+	if err != nil {
+		t.Fatalf("CreateAuthor(%q) failed: %v", created.Name, err)
+	}
+	t.Cleanup(func() {
+		if err := s.repo.DeleteAuthor(context.Background(), id); err != nil {
+			t.Errorf("DeleteAuthor(%d) failed: %v", id, err)
+		}
+	})
+	created.ID = id
+	return created
+}
+
+// date parses a YYYY-MM-DD date, or returns NULL for "".
+func date(t *testing.T, value string) sql.NullTime {
+	t.Helper()
+	if value == "" {
+		return sql.NullTime{}
+	}
+	d, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		t.Fatalf("invalid date %q: %v", value, err)
+	}
+	return sql.NullTime{Time: d, Valid: true}
+}
+
+// ours returns the IDs of the authors among authors that are in created,
+// keeping their order.
+func (s *suite) ours(authors []sqlcgen.Author, created ...sqlcgen.Author) []int32 {
+	var ids []int32
+	for _, a := range authors {
+		if slices.ContainsFunc(created, func(c sqlcgen.Author) bool { return c.ID == a.ID }) {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+func ids(authors ...sqlcgen.Author) []int32 {
+	ids := make([]int32, len(authors))
+	for i, a := range authors {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+// assertAuthor checks that got holds the fields of want, comparing dates of
+// birth by date.
+func assertAuthor(t *testing.T, got, want sqlcgen.Author) {
+	t.Helper()
+	if got.ID != want.ID || got.Name != want.Name || got.Bio != want.Bio || got.Email != want.Email {
+		t.Errorf("author = %+v, want %+v", got, want)
+	}
+	if got.DateOfBirth.Valid != want.DateOfBirth.Valid ||
+		got.DateOfBirth.Time.UTC().Format(time.DateOnly) != want.DateOfBirth.Time.UTC().Format(time.DateOnly) {
+		t.Errorf("date of birth = %v, want %v", got.DateOfBirth, want.DateOfBirth)
+	}
+}
+
+func assertErrorKind(t *testing.T, err error, kind string) {
+	t.Helper()
+	if got := repositories.ErrorKind(err); err == nil || got != kind {
+		t.Errorf("err = %v (%s), want a %s error", err, got, kind)
+	}
+}
+
+func assertIDs(t *testing.T, what string, got, want []int32) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+	}
+}
+
+func (s *suite) testCreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	full := s.create(t, author{name: "Ann", bio: "Writes novels", dateOfBirth: "1990-03-15"})
+	bare := s.create(t, author{name: "Bob"})
+	if full.ID <= 0 || bare.ID <= 0 || full.ID == bare.ID {
+		t.Fatalf("IDs = %d and %d, want distinct positive IDs", full.ID, bare.ID)
+	}
+	for _, want := range []sqlcgen.Author{full, bare} {
+		got, err := s.repo.GetAuthor(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetAuthor(%d) failed: %v", want.ID, err)
+		}
+		assertAuthor(t, got, want)
+	}
+}
+
+func (s *suite) testGetMissing(t *testing.T) {
+	ctx := context.Background()
+	created := s.create(t, author{name: "Ann"})
+	if err := s.repo.DeleteAuthor(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	_, err := s.repo.GetAuthor(ctx, created.ID)
+	assertErrorKind(t, err, repositories.ErrorKindNotFound)
+}
+
+func (s *suite) testUniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	ann := s.create(t, author{name: "Ann", bio: "First"})
+	bob := s.create(t, author{name: "Bob"})
+
+	id, err := s.repo.CreateAuthor(ctx, s.marker+" Copy", sql.NullString{}, ann.Email, sql.NullTime{})
+	if err == nil {
+		// Do not leave the duplicate behind
+		_ = s.repo.DeleteAuthor(ctx, id)
+	}
+	assertErrorKind(t, err, repositories.ErrorKindConstraint)
+
+	err = s.repo.UpdateAuthor(ctx, bob.ID, bob.Name, bob.Bio, ann.Email, bob.DateOfBirth)
+	assertErrorKind(t, err, repositories.ErrorKindConstraint)
+
+	// Neither failed write changed anything
+	for _, want := range []sqlcgen.Author{ann, bob} {
+		got, err := s.repo.GetAuthor(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetAuthor(%d) failed: %v", want.ID, err)
+		}
+		assertAuthor(t, got, want)
+	}
+
+	// An author keeps its own email
+	if err := s.repo.UpdateAuthor(ctx, ann.ID, ann.Name, ann.Bio, ann.Email, ann.DateOfBirth); err != nil {
+		t.Errorf("UpdateAuthor keeping the email failed: %v", err)
+	}
+}
+
+func (s *suite) testUpdate(t *testing.T) {
+	ctx := context.Background()
+	created := s.create(t, author{name: "Ann", bio: "Before", dateOfBirth: "1980-01-01"})
+	want := sqlcgen.Author{
+		ID:          created.ID,
+		Name:        s.marker + " Anne",
+		Bio:         sql.NullString{String: "After", Valid: true},
+		Email:       "updated-" + created.Email,
+		DateOfBirth: date(t, "1981-12-31"),
+	}
+	if err := s.repo.UpdateAuthor(ctx, want.ID, want.Name, want.Bio, want.Email, want.DateOfBirth); err != nil {
+		t.Fatalf("UpdateAuthor failed: %v", err)
+	}
+	got, err := s.repo.GetAuthor(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("GetAuthor failed: %v", err)
+	}
+	assertAuthor(t, got, want)
+
+	// Zero values are written too, clearing the bio and date of birth
+	want.Name, want.Bio, want.DateOfBirth = "", sql.NullString{}, sql.NullTime{}
+	if err := s.repo.UpdateAuthor(ctx, want.ID, want.Name, want.Bio, want.Email, want.DateOfBirth); err != nil {
+		t.Fatalf("UpdateAuthor to zero values failed: %v", err)
+	}
+	got, err = s.repo.GetAuthor(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("GetAuthor failed: %v", err)
+	}
+	assertAuthor(t, got, want)
+
+	// Updating a missing author changes nothing and is not an error
+	missing := s.create(t, author{name: "Bob"})
+	if err := s.repo.DeleteAuthor(ctx, missing.ID); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	if err := s.repo.UpdateAuthor(ctx, missing.ID, missing.Name, missing.Bio, missing.Email, missing.DateOfBirth); err != nil {
+		t.Errorf("UpdateAuthor of a missing author failed: %v", err)
+	}
+	_, err = s.repo.GetAuthor(ctx, missing.ID)
+	assertErrorKind(t, err, repositories.ErrorKindNotFound)
+}
+
+func (s *suite) testDelete(t *testing.T) {
+	ctx := context.Background()
+	deleted := s.create(t, author{name: "Ann"})
+	kept := s.create(t, author{name: "Bob"})
+	if err := s.repo.DeleteAuthor(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	if err := s.repo.DeleteAuthor(ctx, deleted.ID); err != nil {
+		t.Errorf("DeleteAuthor of a missing author failed: %v", err)
+	}
+	authors, err := s.repo.ListAuthors(ctx)
+	if err != nil {
+		t.Fatalf("ListAuthors failed: %v", err)
+	}
+	assertIDs(t, "listed authors", s.ours(authors, deleted, kept), ids(kept))
+}
+
+func (s *suite) testListOrderedByName(t *testing.T) {
+	cid := s.create(t, author{name: "Cid"})
+	ann := s.create(t, author{name: "Ann"})
+	bob := s.create(t, author{name: "Bob"})
+	authors, err := s.repo.ListAuthors(context.Background())
+	if err != nil {
+		t.Fatalf("ListAuthors failed: %v", err)
+	}
+	assertIDs(t, "listed authors", s.ours(authors, cid, ann, bob), ids(ann, bob, cid))
+}
+
+func (s *suite) testBirthdateRange(t *testing.T) {
+	late := s.create(t, author{name: "Late", dateOfBirth: "1990-12-31"})
+	before := s.create(t, author{name: "Before", dateOfBirth: "1989-12-31"})
+	first := s.create(t, author{name: "First", dateOfBirth: "1990-01-01"})
+	after := s.create(t, author{name: "After", dateOfBirth: "1991-01-01"})
+	middle := s.create(t, author{name: "Middle", dateOfBirth: "1990-06-15"})
+	unknown := s.create(t, author{name: "Unknown"})
+
+	// Bounds are compared as dates, so times of day do not matter
+	for _, hour := range []int{0, 12} {
+		start := time.Date(1990, 1, 1, hour, 0, 0, 0, time.UTC)
+		end := time.Date(1990, 12, 31, hour, 0, 0, 0, time.UTC)
+		authors, err := s.repo.GetAuthorsByBirthdateRange(context.Background(), start, end)
+		if err != nil {
+			t.Fatalf("GetAuthorsByBirthdateRange(%v, %v) failed: %v", start, end, err)
+		}
+		assertIDs(t, fmt.Sprintf("authors born in 1990, bounds at %02d:00", hour),
+			s.ours(authors, late, before, first, after, middle, unknown),
+			ids(first, middle, late))
+	}
+}
+
+func (s *suite) testFilter(t *testing.T) {
+	withBio := s.create(t, author{name: "Ann", bio: "Poet", dateOfBirth: "1990-01-01"})
+	noBio := s.create(t, author{name: "Bob", dateOfBirth: "1990-12-31"})
+	early := s.create(t, author{name: "Cid", bio: "Critic", dateOfBirth: "1989-12-31"})
+	unknown := s.create(t, author{name: "Dee", bio: "Editor"})
+	all := []sqlcgen.Author{withBio, noBio, early, unknown}
+
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter repositories.AuthorFilter
+		want   []sqlcgen.Author
+	}{
+		{"name prefix", repositories.AuthorFilter{NamePrefix: s.marker + " B"}, []sqlcgen.Author{noBio}},
+		{"email domain", repositories.AuthorFilter{EmailDomain: s.marker + ".test"}, all},
+		{"other email domain", repositories.AuthorFilter{NamePrefix: s.marker, EmailDomain: "example.com"}, nil},
+		{"with bio", repositories.AuthorFilter{NamePrefix: s.marker, HasBio: &yes}, []sqlcgen.Author{withBio, early, unknown}},
+		{"without bio", repositories.AuthorFilter{NamePrefix: s.marker, HasBio: &no}, []sqlcgen.Author{noBio}},
+		{
+			// Bounds are compared as dates, so times of day do not matter
+			"born between",
+			repositories.AuthorFilter{
+				NamePrefix: s.marker,
+				BornAfter:  time.Date(1990, 1, 1, 12, 0, 0, 0, time.UTC),
+				BornBefore: time.Date(1990, 12, 31, 12, 0, 0, 0, time.UTC),
+			},
+			[]sqlcgen.Author{withBio, noBio},
+		},
+		{
+			"born after",
+			repositories.AuthorFilter{NamePrefix: s.marker, BornAfter: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+			[]sqlcgen.Author{withBio, noBio},
+		},
+		{
+			"every criterion",
+			repositories.AuthorFilter{
+				NamePrefix:  s.marker,
+				EmailDomain: s.marker + ".test",
+				HasBio:      &yes,
+				BornBefore:  time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			[]sqlcgen.Author{withBio, early},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authors, err := s.repo.FilterAuthors(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("FilterAuthors failed: %v", err)
+			}
+			// Sorted by name, which follows creation order here
+			assertIDs(t, "filtered authors", s.ours(authors, all...), ids(tt.want...))
+		})
+	}
+}
+
+func (s *suite) testFilterSort(t *testing.T) {
+	ann := s.create(t, author{name: "Ann", dateOfBirth: "1995-05-05"})
+	bob := s.create(t, author{name: "Bob", dateOfBirth: "1970-07-07"})
+	twin := s.create(t, author{name: "Bob", dateOfBirth: "1980-08-08"})
+	unknown := s.create(t, author{name: "Cid"})
+	all := []sqlcgen.Author{ann, bob, twin, unknown}
+
+	tests := []struct {
+		sortBy    repositories.AuthorSortField
+		direction repositories.SortDirection
+		want      []sqlcgen.Author
+	}{
+		// Ties are broken by ID in the same direction
+		{"", "", []sqlcgen.Author{ann, bob, twin, unknown}},
+		{repositories.SortByName, repositories.Descending, []sqlcgen.Author{unknown, twin, bob, ann}},
+		// Emails are numbered in creation order
+		{repositories.SortByEmail, repositories.Ascending, []sqlcgen.Author{ann, bob, twin, unknown}},
+		{repositories.SortByID, repositories.Descending, []sqlcgen.Author{unknown, twin, bob, ann}},
+		// NULL dates of birth sort last, and first in descending order
+		{repositories.SortByDateOfBirth, repositories.Ascending, []sqlcgen.Author{bob, twin, ann, unknown}},
+		{repositories.SortByDateOfBirth, repositories.Descending, []sqlcgen.Author{unknown, ann, twin, bob}},
+	}
+	for _, tt := range tests {
+		name := cmp.Or(string(tt.sortBy), "default") + " " + cmp.Or(string(tt.direction), "default")
+		t.Run(name, func(t *testing.T) {
+			filter := repositories.AuthorFilter{NamePrefix: s.marker, SortBy: tt.sortBy, Direction: tt.direction}
+			authors, err := s.repo.FilterAuthors(context.Background(), filter)
+			if err != nil {
+				t.Fatalf("FilterAuthors failed: %v", err)
+			}
+			assertIDs(t, "sorted authors", s.ours(authors, all...), ids(tt.want...))
+		})
+	}
+
+	for _, filter := range []repositories.AuthorFilter{{SortBy: "bio"}, {Direction: "up"}} {
+		if _, err := s.repo.FilterAuthors(context.Background(), filter); err == nil {
+			t.Errorf("FilterAuthors(%+v) succeeded, want an invalid sort error", filter)
+		}
+	}
+}
+
+func (s *suite) testSearch(t *testing.T) {
+	ctx := context.Background()
+	term := s.marker + "x"
+	inBio := s.create(t, author{name: "Ann", bio: "Wrote about " + term + " twice"})
+	inName := s.create(t, author{name: "Bob " + term, bio: "Unrelated"})
+	neither := s.create(t, author{name: "Cid", bio: "Unrelated"})
+	all := []sqlcgen.Author{inBio, inName, neither}
+
+	authors, err := s.repo.SearchAuthors(ctx, term, 10)
+	if err != nil {
+		t.Fatalf("SearchAuthors failed: %v", err)
+	}
+	// Matches in the name rank above matches in the bio
+	assertIDs(t, "found authors", s.ours(authors, all...), ids(inName, inBio))
+
+	authors, err = s.repo.SearchAuthors(ctx, term, 1)
+	if err != nil {
+		t.Fatalf("SearchAuthors failed: %v", err)
+	}
+	assertIDs(t, "first found author", ids(authors...), ids(inName))
+
+	authors, err = s.repo.SearchAuthors(ctx, term+" unrelated", 10)
+	if err != nil {
+		t.Fatalf("SearchAuthors failed: %v", err)
+	}
+	assertIDs(t, "authors matching every word", s.ours(authors, all...), ids(inName))
+
+	authors, err = s.repo.SearchAuthors(ctx, term+" -unrelated", 10)
+	if err != nil {
+		t.Fatalf("SearchAuthors failed: %v", err)
+	}
+	assertIDs(t, "authors without the excluded word", s.ours(authors, all...), ids(inBio))
+}
+
+func (s *suite) testCanceled(t *testing.T) {
+	created := s.create(t, author{name: "Ann"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.repo.GetAuthor(ctx, created.ID)
+	assertErrorKind(t, err, repositories.ErrorKindCanceled)
+	_, err = s.repo.ListAuthors(ctx)
+	assertErrorKind(t, err, repositories.ErrorKindCanceled)
+}
@@ -0,0 +1,305 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+)
+
+// TestBookRepository checks that books honors the semantics every
+// BookRepository shares: books are created with their author links in one
+// transaction, loaded with their publisher and authors, listed in title
+// order and deleted without touching their authors. authors must store its
+// authors in the same database, since books link to them.
+//
+// Like TestAuthorRepository, it works on a non-empty database: publishers,
+// authors and ISBNs carry a marker unique to the run, and everything the
+// suite creates is deleted when the test ends.
+func TestBookRepository(t *testing.T, authors repositories.AuthorRepository, books repositories.BookRepository) {
+	s := &bookSuite{
+		suite: &suite{repo: authors, marker: fmt.Sprintf("m%010d", rand.Uint32())},
+		books: books,
+	}
+	t.Run("CreateAndGet", s.testCreateAndGet)
+	t.Run("GetMissing", s.testGetMissing)
+	t.Run("CreateIsAtomic", s.testCreateIsAtomic)
+	t.Run("ListOrderedByTitle", s.testListOrderedByTitle)
+	t.Run("Delete", s.testDelete)
+	t.Run("AuthorsWithBooks", s.testAuthorsWithBooks)
+	t.Run("Canceled", s.testCanceled)
+}
+
+type bookSuite struct {
+	*suite
+	books repositories.BookRepository
+	// publishers and isbns number the publisher names and ISBNs handed out
+	publishers, isbns int
+}
+
+// createPublisher creates a publisher named after the marker and deletes it
+// when the test ends, after the books created later.
+func (s *bookSuite) createPublisher(t *testing.T) sqlcgen.Publisher {
+	t.Helper()
+	s.publishers++
+	created := sqlcgen.Publisher{
+		Name:    fmt.Sprintf("%s Publisher%d", s.marker, s.publishers),
+		Country: sql.NullString{String: "NL", Valid: true},
+	}
+	id, err := s.books.CreatePublisher(context.Background(), created.Name, created.Country)
+	if err != nil {
+		t.Fatalf("CreatePublisher failed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := s.books.DeletePublisher(context.Background(), id); err != nil {
+			t.Errorf("DeletePublisher(%d) failed: %v", id, err)
+		}
+	})
+	created.ID = id
+	return created
+}
+
+// newBook describes a book of publisher with a unique ISBN.
+func (s *bookSuite) newBook(t *testing.T, publisher sqlcgen.Publisher, title, publishedOn string) sqlcgen.Book {
+	t.Helper()
+	s.isbns++
+	return sqlcgen.Book{
+		PublisherID: publisher.ID,
+		Title:       title,
+		Isbn:        fmt.Sprintf("%s-%04d", s.marker, s.isbns),
+		PublishedOn: date(t, publishedOn),
+	}
+}
+
+// createBook creates a book written by authors and deletes it when the test
+// ends.
+func (s *bookSuite) createBook(t *testing.T, publisher sqlcgen.Publisher, title, publishedOn string, authors ...sqlcgen.Author) sqlcgen.Book {
+	t.Helper()
+	book := s.newBook(t, publisher, title, publishedOn)
+	id, err := s.books.CreateBook(context.Background(), book.PublisherID, book.Title, book.Isbn, book.PublishedOn, ids(authors...))
+	if err != nil {
+		t.Fatalf("CreateBook(%q) failed: %v", title, err)
+	}
+	t.Cleanup(func() {
+		if err := s.books.DeleteBook(context.Background(), id); err != nil {
+			t.Errorf("DeleteBook(%d) failed: %v", id, err)
+		}
+	})
+	book.ID = id
+	return book
+}
+
+// assertBook checks that got holds the fields of want, comparing publication
+// dates by date.
+func assertBook(t *testing.T, got, want sqlcgen.Book) {
+	t.Helper()
+	if got.ID != want.ID || got.PublisherID != want.PublisherID || got.Title != want.Title || got.Isbn != want.Isbn {
+		t.Errorf("book = %+v, want %+v", got, want)
+	}
+	if got.PublishedOn.Valid != want.PublishedOn.Valid ||
+		got.PublishedOn.Time.UTC().Format(time.DateOnly) != want.PublishedOn.Time.UTC().Format(time.DateOnly) {
+		t.Errorf("published on = %v, want %v", got.PublishedOn, want.PublishedOn)
+	}
+}
+
+func bookIDs(books ...sqlcgen.Book) []int32 {
+	ids := make([]int32, len(books))
+	for i, b := range books {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func (s *bookSuite) testCreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	publisher := s.createPublisher(t)
+	bob := s.create(t, author{name: "Bob", bio: "Second", dateOfBirth: "1970-07-07"})
+	ann := s.create(t, author{name: "Ann", bio: "First", dateOfBirth: "1960-06-06"})
+	book := s.createBook(t, publisher, "Title", "2001-02-03", bob, ann)
+	bare := s.createBook(t, publisher, "Bare", "")
+
+	details, err := s.books.GetBook(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("GetBook failed: %v", err)
+	}
+	assertBook(t, details.Book, book)
+	if details.Publisher != publisher {
+		t.Errorf("publisher = %+v, want %+v", details.Publisher, publisher)
+	}
+	// Authors are ordered by name, and linking them left them unchanged
+	assertIDs(t, "book authors", ids(details.Authors...), ids(ann, bob))
+	if len(details.Authors) == 2 {
+		assertAuthor(t, details.Authors[0], ann)
+		assertAuthor(t, details.Authors[1], bob)
+	}
+
+	details, err = s.books.GetBook(ctx, bare.ID)
+	if err != nil {
+		t.Fatalf("GetBook failed: %v", err)
+	}
+	assertBook(t, details.Book, bare)
+	if details.Authors == nil || len(details.Authors) != 0 {
+		t.Errorf("authors = %#v, want an empty slice", details.Authors)
+	}
+
+	got, err := s.books.GetPublisher(ctx, publisher.ID)
+	if err != nil || got != publisher {
+		t.Errorf("GetPublisher = %+v, %v, want %+v", got, err, publisher)
+	}
+}
+
+func (s *bookSuite) testGetMissing(t *testing.T) {
+	ctx := context.Background()
+	publisher := s.createPublisher(t)
+	book := s.createBook(t, publisher, "Gone", "")
+	if err := s.books.DeleteBook(ctx, book.ID); err != nil {
+		t.Fatalf("DeleteBook failed: %v", err)
+	}
+	_, err := s.books.GetBook(ctx, book.ID)
+	assertErrorKind(t, err, repositories.ErrorKindNotFound)
+}
+
+func (s *bookSuite) testCreateIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	publisher := s.createPublisher(t)
+	ann := s.create(t, author{name: "Ann"})
+	missing := s.create(t, author{name: "Gone"})
+	if err := s.repo.DeleteAuthor(ctx, missing.ID); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	existing := s.createBook(t, publisher, "Existing", "")
+
+	tests := []struct {
+		name    string
+		book    sqlcgen.Book
+		authors []sqlcgen.Author
+	}{
+		// The book is inserted before the link that fails
+		{"missing author", s.newBook(t, publisher, "Orphan", ""), []sqlcgen.Author{ann, missing}},
+		{"duplicate ISBN", sqlcgen.Book{PublisherID: publisher.ID, Title: "Copy", Isbn: existing.Isbn}, []sqlcgen.Author{ann}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := s.books.CreateBook(ctx, tt.book.PublisherID, tt.book.Title, tt.book.Isbn, tt.book.PublishedOn, ids(tt.authors...))
+			if err == nil {
+				// Do not leave the book behind
+				_ = s.books.DeleteBook(ctx, id)
+			}
+			assertErrorKind(t, err, repositories.ErrorKindConstraint)
+		})
+	}
+
+	// Neither failed create left a book or a link behind
+	books, err := s.books.ListBooksByPublisher(ctx, publisher.ID)
+	if err != nil {
+		t.Fatalf("ListBooksByPublisher failed: %v", err)
+	}
+	assertIDs(t, "books of the publisher", bookIDs(books...), bookIDs(existing))
+	books, err = s.books.ListBooksByAuthor(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("ListBooksByAuthor failed: %v", err)
+	}
+	assertIDs(t, "books of the author", bookIDs(books...), nil)
+}
+
+func (s *bookSuite) testListOrderedByTitle(t *testing.T) {
+	ctx := context.Background()
+	publisher := s.createPublisher(t)
+	ann := s.create(t, author{name: "Ann"})
+	bob := s.create(t, author{name: "Bob"})
+	cid := s.createBook(t, publisher, "Cid", "1999-09-09", ann)
+	alpha := s.createBook(t, publisher, "Alpha", "", ann, bob)
+	beta := s.createBook(t, publisher, "Beta", "", bob)
+
+	books, err := s.books.ListBooksByAuthor(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("ListBooksByAuthor failed: %v", err)
+	}
+	assertIDs(t, "books of Ann", bookIDs(books...), bookIDs(alpha, cid))
+	if len(books) == 2 {
+		assertBook(t, books[1], cid)
+	}
+
+	books, err = s.books.ListBooksByPublisher(ctx, publisher.ID)
+	if err != nil {
+		t.Fatalf("ListBooksByPublisher failed: %v", err)
+	}
+	assertIDs(t, "books of the publisher", bookIDs(books...), bookIDs(alpha, beta, cid))
+}
+
+func (s *bookSuite) testDelete(t *testing.T) {
+	ctx := context.Background()
+	publisher := s.createPublisher(t)
+	ann := s.create(t, author{name: "Ann"})
+	deleted := s.createBook(t, publisher, "Deleted", "", ann)
+	kept := s.createBook(t, publisher, "Kept", "", ann)
+
+	if err := s.books.DeleteBook(ctx, deleted.ID); err != nil {
+		t.Fatalf("DeleteBook failed: %v", err)
+	}
+	if err := s.books.DeleteBook(ctx, deleted.ID); err != nil {
+		t.Errorf("DeleteBook of a missing book failed: %v", err)
+	}
+	books, err := s.books.ListBooksByAuthor(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("ListBooksByAuthor failed: %v", err)
+	}
+	assertIDs(t, "books of the author", bookIDs(books...), bookIDs(kept))
+
+	// The links of an author go with the author, the books stay
+	if err := s.repo.DeleteAuthor(ctx, ann.ID); err != nil {
+		t.Fatalf("DeleteAuthor failed: %v", err)
+	}
+	details, err := s.books.GetBook(ctx, kept.ID)
+	if err != nil {
+		t.Fatalf("GetBook failed: %v", err)
+	}
+	if len(details.Authors) != 0 {
+		t.Errorf("authors = %v, want none after deleting the author", ids(details.Authors...))
+	}
+}
+
+func (s *bookSuite) testAuthorsWithBooks(t *testing.T) {
+	publisher := s.createPublisher(t)
+	bob := s.create(t, author{name: "Bob"})
+	ann := s.create(t, author{name: "Ann"})
+	cid := s.create(t, author{name: "Cid"})
+	zeta := s.createBook(t, publisher, "Zeta", "", ann, bob)
+	alpha := s.createBook(t, publisher, "Alpha", "", ann)
+
+	authors, err := s.books.ListAuthorsWithBooks(context.Background(), ids(cid, bob, ann))
+	if err != nil {
+		t.Fatalf("ListAuthorsWithBooks failed: %v", err)
+	}
+	// Ordered by name, leaving out Cid, who has no books
+	got := make([]sqlcgen.Author, len(authors))
+	for i, a := range authors {
+		got[i] = a.Author
+	}
+	assertIDs(t, "authors with books", ids(got...), ids(ann, bob))
+	if len(authors) != 2 {
+		return
+	}
+	assertAuthor(t, authors[0].Author, ann)
+	assertIDs(t, "books of Ann", bookIDs(authors[0].Books...), bookIDs(alpha, zeta))
+	assertIDs(t, "books of Bob", bookIDs(authors[1].Books...), bookIDs(zeta))
+	if len(authors[0].Books) == 2 {
+		assertBook(t, authors[0].Books[1], zeta)
+	}
+}
+
+func (s *bookSuite) testCanceled(t *testing.T) {
+	publisher := s.createPublisher(t)
+	book := s.createBook(t, publisher, "Title", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.books.GetBook(ctx, book.ID)
+	assertErrorKind(t, err, repositories.ErrorKindCanceled)
+	_, err = s.books.CreateBook(ctx, publisher.ID, "Canceled", s.marker+"-canceled", sql.NullTime{}, nil)
+	assertErrorKind(t, err, repositories.ErrorKindCanceled)
+}
@@ -0,0 +1,93 @@
+package roundtrip
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Counter counts network round-trips on the connections it wraps. A
+// round-trip is counted whenever the client starts reading after having
+// written, so pipelined messages sent before waiting for a reply count once.
+type Counter struct {
+	count atomic.Int64
+}
+
+// NewCounter creates a counter starting at zero.
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// Count returns the number of round-trips since the last reset.
+func (c *Counter) Count() int64 {
+	return c.count.Load()
+}
+
+// Reset sets the count back to zero.
+func (c *Counter) Reset() {
+	c.count.Store(0)
+}
+
+// Wrap returns a connection that reports its round-trips to the counter.
+func (c *Counter) Wrap(conn net.Conn) net.Conn {
+	return &countingConn{Conn: conn, counter: c}
+}
+
+const (
+	opNone int32 = iota
+	opWrite
+	opRead
+)
+
+type countingConn struct {
+	net.Conn
+	counter *Counter
+	lastOp  atomic.Int32
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.lastOp.Store(opWrite)
+	return c.Conn.Write(b)
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	if c.lastOp.Swap(opRead) == opWrite {
+		c.counter.count.Add(1)
+	}
+	return c.Conn.Read(b)
+}
+
+// Dialer opens TCP connections that report their round-trips to a counter.
+// It implements the lib/pq Dialer interfaces, and its DialContext method can
+// be used as the DialFunc of a pgx connection config.
+type Dialer struct {
+	counter *Counter
+	dialer  net.Dialer
+}
+
+// NewDialer creates a dialer reporting to counter.
+func NewDialer(counter *Counter) *Dialer {
+	return &Dialer{counter: counter, dialer: net.Dialer{KeepAlive: 5 * time.Minute}}
+}
+
+// Dial connects to address.
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialTimeout connects to address, giving up after timeout.
+func (d *Dialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+// DialContext connects to address using ctx.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return d.counter.Wrap(conn), nil
+}
@@ -0,0 +1,136 @@
+package roundtrip
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// serve reads requests one-byte requests at a time from conn and answers each
+// batch with a two-byte reply, until conn is closed.
+func serve(conn net.Conn, requests int) {
+	defer conn.Close()
+	request := make([]byte, requests)
+	for {
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte("ok")); err != nil {
+			return
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	tests := []struct {
+		name string
+		// requests is the number of requests the client writes before reading
+		// each reply
+		requests int
+		// exchanges is the number of times it does so
+		exchanges int
+		// readSize is the size of the reads the reply is consumed with
+		readSize int
+		want     int64
+	}{
+		{name: "write then read", requests: 1, exchanges: 1, readSize: 2, want: 1},
+		{name: "reply read in several reads", requests: 1, exchanges: 1, readSize: 1, want: 1},
+		{name: "pipelined writes", requests: 3, exchanges: 1, readSize: 2, want: 1},
+		{name: "several exchanges", requests: 1, exchanges: 4, readSize: 2, want: 4},
+		{name: "several pipelined exchanges", requests: 2, exchanges: 3, readSize: 1, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			go serve(server, tt.requests)
+			counter := NewCounter()
+			conn := counter.Wrap(client)
+			defer conn.Close()
+
+			reply := make([]byte, tt.readSize)
+			for range tt.exchanges {
+				for range tt.requests {
+					if _, err := conn.Write([]byte{'q'}); err != nil {
+						t.Fatalf("write failed: %v", err)
+					}
+				}
+				for read := 0; read < 2; read += tt.readSize {
+					if _, err := io.ReadFull(conn, reply); err != nil {
+						t.Fatalf("read failed: %v", err)
+					}
+				}
+			}
+			if got := counter.Count(); got != tt.want {
+				t.Errorf("Count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterReadWithoutWrite(t *testing.T) {
+	client, server := net.Pipe()
+	counter := NewCounter()
+	conn := counter.Wrap(client)
+	defer conn.Close()
+	// A server greeting the client is not a round-trip of the client's
+	go func() {
+		server.Write([]byte("hi"))
+		server.Close()
+	}()
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if got := counter.Count(); got != 0 {
+		t.Errorf("Count = %d, want 0", got)
+	}
+}
+
+func TestCounterSharedAndReset(t *testing.T) {
+	counter := NewCounter()
+	for range 2 {
+		client, server := net.Pipe()
+		go serve(server, 1)
+		conn := counter.Wrap(client)
+		conn.Write([]byte{'q'})
+		io.ReadFull(conn, make([]byte, 2))
+		conn.Close()
+	}
+	if got := counter.Count(); got != 2 {
+		t.Errorf("Count over two connections = %d, want 2", got)
+	}
+	counter.Reset()
+	if got := counter.Count(); got != 0 {
+		t.Errorf("Count after Reset = %d, want 0", got)
+	}
+}
+
+func TestDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		serve(conn, 1)
+	}()
+
+	counter := NewCounter()
+	conn, err := NewDialer(counter).Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte{'q'}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 2)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if got := counter.Count(); got != 1 {
+		t.Errorf("Count = %d, want 1", got)
+	}
+}
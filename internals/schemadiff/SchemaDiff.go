@@ -0,0 +1,210 @@
+package schemadiff
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ignoredTables are bookkeeping tables that are not part of the compared schema.
+var ignoredTables = map[string]bool{"schema_migrations": true}
+
+// Schema is the introspected structure of the tables in a PostgreSQL schema.
+type Schema map[string]*Table
+
+// Table holds the columns, indexes and constraints of a table. Indexes and
+// constraints are keyed by their definition without their name, since GORM
+// and hand-written SQL name them differently.
+type Table struct {
+	Columns     map[string]Column
+	Indexes     map[string]bool
+	Constraints map[string]bool
+}
+
+// Column describes a table column.
+type Column struct {
+	Type     string
+	Nullable bool
+	Default  string
+}
+
+func (c Column) String() string {
+	nullability := "NOT NULL"
+	if c.Nullable {
+		nullability = "NULL"
+	}
+	if c.Default != "" {
+		return fmt.Sprintf("%s %s DEFAULT %s", c.Type, nullability, c.Default)
+	}
+	return fmt.Sprintf("%s %s", c.Type, nullability)
+}
+
+// Difference is a single mismatch between two schemas. Left or Right is empty
+// when the object only exists on the other side.
+type Difference struct {
+	Table  string
+	Kind   string
+	Object string
+	Left   string
+	Right  string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s %s %s: %q vs %q", d.Table, d.Kind, d.Object, d.Left, d.Right)
+}
+
+var indexNamePattern = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX \S+ ON (\S+\.)?`)
+
+// indexKey strips the index name and the table's schema from an index
+// definition returned by pg_get_indexdef.
+func indexKey(definition string) string {
+	return indexNamePattern.ReplaceAllString(definition, "CREATE ${1}INDEX ON ")
+}
+
+// Inspect reads the tables of schemaName from pg_catalog.
+func Inspect(ctx context.Context, db *sql.DB, schemaName string) (Schema, error) {
+	schema := Schema{}
+	table := func(name string) *Table {
+		t, ok := schema[name]
+		if !ok {
+			t = &Table{Columns: map[string]Column{}, Indexes: map[string]bool{}, Constraints: map[string]bool{}}
+			schema[name] = t
+		}
+		return t
+	}
+
+	rows, err := db.QueryContext(ctx, `
+SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull,
+       COALESCE(pg_get_expr(d.adbin, d.adrelid), '')
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, columnName string
+		var column Column
+		if err := rows.Scan(&tableName, &columnName, &column.Type, &column.Nullable, &column.Default); err != nil {
+			return nil, err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).Columns[columnName] = column
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+SELECT c.relname, pg_get_indexdef(i.indexrelid)
+FROM pg_index i
+JOIN pg_class c ON c.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, definition string
+		if err := rows.Scan(&tableName, &definition); err != nil {
+			return nil, err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).Indexes[indexKey(definition)] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+SELECT c.relname, pg_get_constraintdef(k.oid)
+FROM pg_constraint k
+JOIN pg_class c ON c.oid = k.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read constraints: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, definition string
+		if err := rows.Scan(&tableName, &definition); err != nil {
+			return nil, err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).Constraints[definition] = true
+		}
+	}
+	return schema, rows.Err()
+}
+
+// Compare returns the differences between two schemas, sorted by table.
+func Compare(left, right Schema) []Difference {
+	var diffs []Difference
+	for _, name := range unionKeys(left, right) {
+		l, r := left[name], right[name]
+		switch {
+		case l == nil:
+			diffs = append(diffs, Difference{Table: name, Kind: "table", Object: name, Right: "present"})
+			continue
+		case r == nil:
+			diffs = append(diffs, Difference{Table: name, Kind: "table", Object: name, Left: "present"})
+			continue
+		}
+
+		for _, column := range unionKeys(l.Columns, r.Columns) {
+			lc, lok := l.Columns[column]
+			rc, rok := r.Columns[column]
+			if lok && rok && lc == rc {
+				continue
+			}
+			diff := Difference{Table: name, Kind: "column", Object: column}
+			if lok {
+				diff.Left = lc.String()
+			}
+			if rok {
+				diff.Right = rc.String()
+			}
+			diffs = append(diffs, diff)
+		}
+		diffs = append(diffs, compareSets(name, "index", l.Indexes, r.Indexes)...)
+		diffs = append(diffs, compareSets(name, "constraint", l.Constraints, r.Constraints)...)
+	}
+	return diffs
+}
+
+func compareSets(table, kind string, left, right map[string]bool) []Difference {
+	var diffs []Difference
+	for _, definition := range unionKeys(left, right) {
+		switch {
+		case !right[definition]:
+			diffs = append(diffs, Difference{Table: table, Kind: kind, Object: definition, Left: "present"})
+		case !left[definition]:
+			diffs = append(diffs, Difference{Table: table, Kind: kind, Object: definition, Right: "present"})
+		}
+	}
+	return diffs
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package schemadiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func authorsTable() *Table {
+	return &Table{
+		Columns: map[string]Column{
+			"id":   {Type: "bigint", Default: "nextval('authors_id_seq'::regclass)"},
+			"name": {Type: "text"},
+			"bio":  {Type: "text", Nullable: true},
+		},
+		Indexes: map[string]bool{
+			indexKey("CREATE UNIQUE INDEX authors_pkey ON public.authors USING btree (id)"): true,
+			indexKey("CREATE INDEX idx_authors_name ON public.authors USING btree (name)"):  true,
+		},
+		Constraints: map[string]bool{"PRIMARY KEY (id)": true},
+	}
+}
+
+func TestIndexKey(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       string
+	}{
+		{"CREATE INDEX idx_authors_name ON public.authors USING btree (name)", "CREATE INDEX ON authors USING btree (name)"},
+		{"CREATE INDEX authors_name_idx ON authors USING btree (name)", "CREATE INDEX ON authors USING btree (name)"},
+		{"CREATE UNIQUE INDEX authors_email_key ON public.authors USING btree (email)", "CREATE UNIQUE INDEX ON authors USING btree (email)"},
+		{"CREATE UNIQUE INDEX idx_authors_email ON sqlc.authors USING btree (email)", "CREATE UNIQUE INDEX ON authors USING btree (email)"},
+	}
+	for _, tt := range tests {
+		if got := indexKey(tt.definition); got != tt.want {
+			t.Errorf("indexKey(%q) = %q, want %q", tt.definition, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(right Schema)
+		want   []Difference
+	}{
+		{
+			name:   "identical",
+			change: func(Schema) {},
+		},
+		{
+			name: "renamed index",
+			change: func(right Schema) {
+				right["authors"].Indexes = map[string]bool{
+					indexKey("CREATE UNIQUE INDEX authors_pkey ON sqlc.authors USING btree (id)"): true,
+					indexKey("CREATE INDEX authors_name_idx ON sqlc.authors USING btree (name)"):  true,
+				}
+			},
+		},
+		{
+			name: "type change",
+			change: func(right Schema) {
+				right["authors"].Columns["name"] = Column{Type: "character varying(255)"}
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "column", Object: "name", Left: "text NOT NULL", Right: "character varying(255) NOT NULL"},
+			},
+		},
+		{
+			name: "nullability change",
+			change: func(right Schema) {
+				right["authors"].Columns["bio"] = Column{Type: "text"}
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "column", Object: "bio", Left: "text NULL", Right: "text NOT NULL"},
+			},
+		},
+		{
+			name: "column on one side only",
+			change: func(right Schema) {
+				right["authors"].Columns["email"] = Column{Type: "text"}
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "column", Object: "email", Right: "text NOT NULL"},
+			},
+		},
+		{
+			name: "missing index",
+			change: func(right Schema) {
+				delete(right["authors"].Indexes, "CREATE INDEX ON authors USING btree (name)")
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "index", Object: "CREATE INDEX ON authors USING btree (name)", Left: "present"},
+			},
+		},
+		{
+			name: "missing constraint",
+			change: func(right Schema) {
+				right["authors"].Constraints = map[string]bool{}
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "constraint", Object: "PRIMARY KEY (id)", Left: "present"},
+			},
+		},
+		{
+			name: "extra constraint",
+			change: func(right Schema) {
+				right["authors"].Constraints["UNIQUE (name)"] = true
+			},
+			want: []Difference{
+				{Table: "authors", Kind: "constraint", Object: "UNIQUE (name)", Right: "present"},
+			},
+		},
+		{
+			name: "table on one side only",
+			change: func(right Schema) {
+				right["books"] = &Table{Columns: map[string]Column{"id": {Type: "bigint"}}}
+			},
+			want: []Difference{
+				{Table: "books", Kind: "table", Object: "books", Right: "present"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left := Schema{"authors": authorsTable()}
+			right := Schema{"authors": authorsTable()}
+			tt.change(right)
+
+			if got := Compare(left, right); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare = %v, want %v", got, tt.want)
+			}
+			// Swapping the schemas swaps the sides of every difference
+			var swapped []Difference
+			for _, diff := range tt.want {
+				diff.Left, diff.Right = diff.Right, diff.Left
+				swapped = append(swapped, diff)
+			}
+			if got := Compare(right, left); !reflect.DeepEqual(got, swapped) {
+				t.Errorf("Compare swapped = %v, want %v", got, swapped)
+			}
+		})
+	}
+}
+
+func TestCompareSortsByTable(t *testing.T) {
+	left := Schema{"publishers": authorsTable(), "authors": authorsTable()}
+	right := Schema{"books": authorsTable()}
+	var tables []string
+	for _, diff := range Compare(left, right) {
+		tables = append(tables, diff.Table)
+	}
+	if want := []string{"authors", "books", "publishers"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("tables = %v, want %v", tables, want)
+	}
+}
+
+func TestCompareSets(t *testing.T) {
+	left := map[string]bool{"a": true, "b": true}
+	right := map[string]bool{"b": true, "c": true}
+	want := []Difference{
+		{Table: "t", Kind: "index", Object: "a", Left: "present"},
+		{Table: "t", Kind: "index", Object: "c", Right: "present"},
+	}
+	if got := compareSets("t", "index", left, right); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareSets = %v, want %v", got, want)
+	}
+	if got := compareSets("t", "index", left, left); got != nil {
+		t.Errorf("compareSets of equal sets = %v, want none", got)
+	}
+}
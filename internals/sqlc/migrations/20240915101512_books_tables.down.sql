@@ -0,0 +1,6 @@
+-- down.sql
+
+-- Drop the tables in reverse dependency order
+DROP TABLE IF EXISTS book_authors;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS publishers;
@@ -0,0 +1,28 @@
+-- up.sql
+
+-- Create publishers table
+CREATE TABLE publishers (
+  id SERIAL PRIMARY KEY,
+  name TEXT UNIQUE NOT NULL,
+  country TEXT
+);
+
+-- Create books table, each book belongs to one publisher
+CREATE TABLE books (
+  id SERIAL PRIMARY KEY,
+  publisher_id INTEGER NOT NULL REFERENCES publishers (id),
+  title TEXT NOT NULL,
+  isbn TEXT UNIQUE NOT NULL,
+  published_on DATE
+);
+
+CREATE INDEX books_publisher_id_idx ON books (publisher_id);
+
+-- Create book_authors join table, linking books and authors many-to-many
+CREATE TABLE book_authors (
+  book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+  author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+  PRIMARY KEY (book_id, author_id)
+);
+
+CREATE INDEX book_authors_author_id_idx ON book_authors (author_id);
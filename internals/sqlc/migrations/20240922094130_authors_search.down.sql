@@ -0,0 +1,5 @@
+-- down.sql
+
+-- Drop the full-text search document and its index
+DROP INDEX IF EXISTS authors_search_idx;
+ALTER TABLE authors DROP COLUMN IF EXISTS search;
@@ -0,0 +1,11 @@
+-- up.sql
+
+-- Add a full-text search document over the author's name and bio, kept up
+-- to date by PostgreSQL. Name matches rank above bio matches.
+ALTER TABLE authors
+  ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', name), 'A') ||
+    setweight(to_tsvector('english', coalesce(bio, '')), 'B')
+  ) STORED;
+
+CREATE INDEX authors_search_idx ON authors USING GIN (search);
@@ -0,0 +1,321 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// versionTable has the layout used by the golang-migrate CLI, so databases
+// migrated with `migrate` and with this package can be mixed.
+const versionTable = "schema_migrations"
+
+// lockID is the advisory lock taken while migrating, so concurrent runners
+// do not apply the same migration twice.
+const lockID = 4_917_253_301
+
+// ErrUnversionedSchema is returned when migrating up a schema that already
+// holds tables not created by the migrations.
+var ErrUnversionedSchema = errors.New("schema was not created by the migrations")
+
+var filePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a pair of embedded up and down scripts.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes the migration state of a database.
+type Status struct {
+	// Current is the version of the last applied migration, or 0.
+	Current uint64
+	// Dirty is set when a migration applied by the migrate CLI failed half-way.
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator applies the embedded migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads the embedded migrations.
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrations returns the embedded migrations ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up or down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return nil
+		}
+		idx := m.index(current)
+		if idx < 0 {
+			return fmt.Errorf("database is at unknown migration version %d", current)
+		}
+		var previous uint64
+		if idx > 0 {
+			previous = m.migrations[idx-1].Version
+		}
+		return m.migrate(ctx, conn, current, previous)
+	})
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 rolls back every migration.
+func (m *Migrator) To(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == 0 && version > 0 {
+			if err := checkUnversioned(ctx, conn); err != nil {
+				return err
+			}
+		}
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Status reports the current version and the applied and pending migrations.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return Status{}, err
+	}
+	var status Status
+	err = conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+versionTable+" LIMIT 1").Scan(&status.Current, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Status{}, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	for _, migration := range m.migrations {
+		if migration.Version <= status.Current {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+func (m *Migrator) index(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrate runs the up scripts after current through target, or the down
+// scripts from current back to target, one transaction per migration.
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current, target uint64) error {
+	if target >= current {
+		for _, migration := range m.migrations {
+			if migration.Version > current && migration.Version <= target {
+				if err := apply(ctx, conn, migration.Up, migration.Version); err != nil {
+					return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		var previous uint64
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+		if err := apply(ctx, conn, migration.Down, previous); err != nil {
+			return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// apply runs script and records version as the current one atomically.
+func apply(ctx context.Context, conn *sql.Conn, script string, version uint64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+versionTable); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+versionTable+" (version, dirty) VALUES ($1, false)", version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// withLock runs fn on a single connection holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(*sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+versionTable+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", versionTable, err)
+	}
+	return nil
+}
+
+// checkUnversioned fails when the current schema already has tables although
+// no migration is recorded, as left behind by GORM's AutoMigrate in earlier
+// versions of the benchmark, instead of failing on the first CREATE TABLE.
+func checkUnversioned(ctx context.Context, conn *sql.Conn) error {
+	rows, err := conn.QueryContext(ctx, `SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_name <> $1 ORDER BY table_name`, versionTable)
+	if err != nil {
+		return fmt.Errorf("failed to list existing tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return fmt.Errorf("failed to list existing tables: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list existing tables: %w", err)
+	}
+	if len(tables) > 0 {
+		return fmt.Errorf("%w: found tables %s without a recorded migration version; "+
+			"drop them, or the whole schema, once and migrate again", ErrUnversionedSchema, strings.Join(tables, ", "))
+	}
+	return nil
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (uint64, error) {
+	var version uint64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+versionTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at migration version %d, fix it manually and force the version", version)
+	}
+	return version, nil
+}
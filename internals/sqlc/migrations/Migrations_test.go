@@ -0,0 +1,277 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/config"
+)
+
+// openTestDB connects to the SQLC test database with a fresh schema selected,
+// dropped again when the test ends, and skips the test when the database is
+// not reachable.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Skipf("database not available: %v", err)
+	}
+	admin, err := sql.Open("postgres", cfg.SQLC.DSN)
+	if err != nil {
+		t.Skipf("database not available: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	if err := admin.Ping(); err != nil {
+		t.Skipf("database not available: %v", err)
+	}
+
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + pq.QuoteIdentifier(schema)); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE"); err != nil {
+			t.Errorf("failed to drop schema: %v", err)
+		}
+	})
+
+	db, err := sql.Open("postgres", config.DatabaseConfig{DSN: cfg.SQLC.DSN, Schema: schema}.ConnString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+	db := openTestDB(t)
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if len(migrator.Migrations()) < 3 {
+		t.Fatalf("got %d migrations, want at least 3", len(migrator.Migrations()))
+	}
+	return migrator, db
+}
+
+// tables returns the tables of the current schema, apart from the version table.
+func tables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_name <> $1 ORDER BY table_name`, versionTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func assertStatus(t *testing.T, migrator *Migrator, current uint64, applied, pending int) {
+	t.Helper()
+	status, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Current != current || status.Dirty || len(status.Applied) != applied || len(status.Pending) != pending {
+		t.Errorf("status = version %d (dirty %t), %d applied, %d pending, want version %d, %d applied, %d pending",
+			status.Current, status.Dirty, len(status.Applied), len(status.Pending), current, applied, pending)
+	}
+}
+
+func TestUpDownRoundTrip(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+	all := migrator.Migrations()
+	last := all[len(all)-1].Version
+
+	assertStatus(t, migrator, 0, 0, len(all))
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	assertStatus(t, migrator, last, len(all), 0)
+	if got := strings.Join(tables(t, db), ","); got != "authors,book_authors,books,publishers" {
+		t.Errorf("tables after Up = %s", got)
+	}
+	// Up is idempotent
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("second Up failed: %v", err)
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		if err := migrator.Down(ctx); err != nil {
+			t.Fatalf("Down from %d failed: %v", all[i].Version, err)
+		}
+		var previous uint64
+		if i > 0 {
+			previous = all[i-1].Version
+		}
+		assertStatus(t, migrator, previous, i, len(all)-i)
+	}
+	if got := tables(t, db); len(got) != 0 {
+		t.Errorf("tables after rolling everything back = %v", got)
+	}
+	// Down with nothing applied does nothing
+	if err := migrator.Down(ctx); err != nil {
+		t.Fatalf("Down at version 0 failed: %v", err)
+	}
+}
+
+func TestTo(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+	all := migrator.Migrations()
+	first, last := all[0].Version, all[len(all)-1].Version
+
+	if err := migrator.To(ctx, first); err != nil {
+		t.Fatalf("To(%d) failed: %v", first, err)
+	}
+	assertStatus(t, migrator, first, 1, len(all)-1)
+	if got := strings.Join(tables(t, db), ","); got != "authors" {
+		t.Errorf("tables at %d = %s, want authors", first, got)
+	}
+
+	if err := migrator.To(ctx, last); err != nil {
+		t.Fatalf("To(%d) failed: %v", last, err)
+	}
+	assertStatus(t, migrator, last, len(all), 0)
+
+	if err := migrator.To(ctx, first); err != nil {
+		t.Fatalf("To(%d) going down failed: %v", first, err)
+	}
+	assertStatus(t, migrator, first, 1, len(all)-1)
+
+	if err := migrator.To(ctx, 0); err != nil {
+		t.Fatalf("To(0) failed: %v", err)
+	}
+	assertStatus(t, migrator, 0, 0, len(all))
+
+	if err := migrator.To(ctx, first+1); err == nil || !strings.Contains(err.Error(), "unknown migration version") {
+		t.Errorf("To an unknown version = %v, want an error", err)
+	}
+}
+
+func TestUpRefusesUnversionedSchema(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	// As left behind by GORM's AutoMigrate
+	if _, err := db.Exec("CREATE TABLE authors (id BIGSERIAL PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	err := migrator.Up(context.Background())
+	if !errors.Is(err, ErrUnversionedSchema) || !strings.Contains(err.Error(), "authors") {
+		t.Errorf("Up = %v, want %v naming authors", err, ErrUnversionedSchema)
+	}
+	assertStatus(t, migrator, 0, 0, len(migrator.Migrations()))
+}
+
+func TestDirtyDatabase(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	first := migrator.Migrations()[0].Version
+	if err := migrator.To(context.Background(), first); err != nil {
+		t.Fatalf("To failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE " + versionTable + " SET dirty = true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Errorf("Up on a dirty database = %v, want an error", err)
+	}
+}
+
+func TestConcurrentUp(t *testing.T) {
+	migrator, _ := newTestMigrator(t)
+	// Without the advisory lock both runners would apply the first migration
+	// and one of them would fail on CREATE TABLE
+	const runners = 4
+	errs := make([]error, runners)
+	var wg sync.WaitGroup
+	for i := range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = migrator.Up(context.Background())
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("runner %d: Up failed: %v", i, err)
+		}
+	}
+	all := migrator.Migrations()
+	assertStatus(t, migrator, all[len(all)-1].Version, len(all), 0)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []uint64
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"2_second.up.sql":   {Data: []byte("up 2")},
+				"2_second.down.sql": {Data: []byte("down 2")},
+				"10_third.up.sql":   {Data: []byte("up 10")},
+				"10_third.down.sql": {Data: []byte("down 10")},
+				"1_first.up.sql":    {Data: []byte("up 1")},
+				"1_first.down.sql":  {Data: []byte("down 1")},
+				"README.md":         {Data: []byte("ignored")},
+			},
+			want: []uint64{1, 2, 10},
+		},
+		{
+			name:    "missing down script",
+			files:   fstest.MapFS{"1_first.up.sql": {Data: []byte("up 1")}},
+			wantErr: "missing its up or down script",
+		},
+		{
+			name:    "missing up script",
+			files:   fstest.MapFS{"1_first.down.sql": {Data: []byte("down 1")}},
+			wantErr: "missing its up or down script",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("load = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			var versions []uint64
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+				if m.Up != fmt.Sprintf("up %d", m.Version) || m.Down != fmt.Sprintf("down %d", m.Version) {
+					t.Errorf("migration %d scripts = %q and %q", m.Version, m.Up, m.Down)
+				}
+			}
+			if fmt.Sprint(versions) != fmt.Sprint(tt.want) {
+				t.Errorf("versions = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
@@ -27,3 +27,31 @@ WHERE id = $1;
 SELECT id, name, bio, email, date_of_birth FROM authors
 WHERE date_of_birth BETWEEN $1 AND $2
 ORDER BY date_of_birth;
+
+-- name: ListAuthorsByIDs :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE id = ANY(@ids::int[])
+ORDER BY name;
+
+-- name: SearchAuthors :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE search @@ websearch_to_tsquery('english', @query)
+ORDER BY ts_rank(search, websearch_to_tsquery('english', @query)) DESC, id
+LIMIT sqlc.arg('limit');
+
+-- name: FilterAuthors :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR starts_with(name, sqlc.narg('name_prefix')::text))
+  AND (sqlc.narg('email_domain')::text IS NULL OR split_part(email, '@', 2) = sqlc.narg('email_domain')::text)
+  AND (sqlc.narg('has_bio')::boolean IS NULL OR (coalesce(bio, '') <> '') = sqlc.narg('has_bio')::boolean)
+  AND (sqlc.narg('born_after')::date IS NULL OR date_of_birth >= sqlc.narg('born_after')::date)
+  AND (sqlc.narg('born_before')::date IS NULL OR date_of_birth <= sqlc.narg('born_before')::date)
+ORDER BY
+  CASE WHEN @sort_field::text = 'name' AND NOT @sort_desc::boolean THEN name END ASC,
+  CASE WHEN @sort_field::text = 'name' AND @sort_desc::boolean THEN name END DESC,
+  CASE WHEN @sort_field::text = 'email' AND NOT @sort_desc::boolean THEN email END ASC,
+  CASE WHEN @sort_field::text = 'email' AND @sort_desc::boolean THEN email END DESC,
+  CASE WHEN @sort_field::text = 'date_of_birth' AND NOT @sort_desc::boolean THEN date_of_birth END ASC,
+  CASE WHEN @sort_field::text = 'date_of_birth' AND @sort_desc::boolean THEN date_of_birth END DESC,
+  CASE WHEN @sort_desc::boolean THEN id END DESC,
+  id ASC;
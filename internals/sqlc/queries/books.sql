@@ -0,0 +1,58 @@
+-- name: CreatePublisher :one
+INSERT INTO publishers (name, country)
+VALUES ($1, $2)
+RETURNING id;
+
+-- name: GetPublisher :one
+SELECT id, name, country FROM publishers
+WHERE id = $1;
+
+-- name: DeletePublisher :exec
+DELETE FROM publishers
+WHERE id = $1;
+
+-- name: CreateBook :one
+INSERT INTO books (publisher_id, title, isbn, published_on)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+
+-- name: AddBookAuthor :exec
+INSERT INTO book_authors (book_id, author_id)
+VALUES ($1, $2);
+
+-- name: GetBookWithPublisher :one
+SELECT sqlc.embed(books), sqlc.embed(publishers)
+FROM books
+JOIN publishers ON publishers.id = books.publisher_id
+WHERE books.id = $1;
+
+-- name: ListAuthorsByBook :many
+SELECT authors.id, authors.name, authors.bio, authors.email, authors.date_of_birth
+FROM authors
+JOIN book_authors ON book_authors.author_id = authors.id
+WHERE book_authors.book_id = $1
+ORDER BY authors.name;
+
+-- name: ListBooksByAuthor :many
+SELECT books.id, books.publisher_id, books.title, books.isbn, books.published_on
+FROM books
+JOIN book_authors ON book_authors.book_id = books.id
+WHERE book_authors.author_id = $1
+ORDER BY books.title;
+
+-- name: ListBooksByPublisher :many
+SELECT id, publisher_id, title, isbn, published_on FROM books
+WHERE publisher_id = $1
+ORDER BY title;
+
+-- name: DeleteBook :exec
+DELETE FROM books
+WHERE id = $1;
+
+-- name: ListAuthorsWithBooks :many
+SELECT sqlc.embed(authors), sqlc.embed(books)
+FROM authors
+JOIN book_authors ON book_authors.author_id = authors.id
+JOIN books ON books.id = book_authors.book_id
+WHERE authors.id = ANY(@ids::int[])
+ORDER BY authors.name, authors.id, books.title;
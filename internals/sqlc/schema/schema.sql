@@ -6,3 +6,35 @@ CREATE TABLE authors (
   email TEXT UNIQUE NOT NULL,
   date_of_birth DATE
 );
+
+-- The generated authors.search tsvector column and its GIN index are only
+-- created by the 20240922094130_authors_search migration. Declaring the
+-- column here would add it to the Author model shared by every author query
+-- and by GORM, which cannot write to a generated column.
+
+-- Publishers own many books
+CREATE TABLE publishers (
+  id SERIAL PRIMARY KEY,
+  name TEXT UNIQUE NOT NULL,
+  country TEXT
+);
+
+-- Each book belongs to one publisher
+CREATE TABLE books (
+  id SERIAL PRIMARY KEY,
+  publisher_id INTEGER NOT NULL REFERENCES publishers (id),
+  title TEXT NOT NULL,
+  isbn TEXT UNIQUE NOT NULL,
+  published_on DATE
+);
+
+CREATE INDEX books_publisher_id_idx ON books (publisher_id);
+
+-- Many-to-many link between books and authors
+CREATE TABLE book_authors (
+  book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+  author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+  PRIMARY KEY (book_id, author_id)
+);
+
+CREATE INDEX book_authors_author_id_idx ON book_authors (author_id);
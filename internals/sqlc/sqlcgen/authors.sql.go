@@ -8,6 +8,8 @@ package sqlcgen
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const CreateAuthor = `-- name: CreateAuthor :one
@@ -45,6 +47,71 @@ func (q *Queries) DeleteAuthor(ctx context.Context, id int32) error {
 	return err
 }
 
+const FilterAuthors = `-- name: FilterAuthors :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE ($1::text IS NULL OR starts_with(name, $1::text))
+  AND ($2::text IS NULL OR split_part(email, '@', 2) = $2::text)
+  AND ($3::boolean IS NULL OR (coalesce(bio, '') <> '') = $3::boolean)
+  AND ($4::date IS NULL OR date_of_birth >= $4::date)
+  AND ($5::date IS NULL OR date_of_birth <= $5::date)
+ORDER BY
+  CASE WHEN $6::text = 'name' AND NOT $7::boolean THEN name END ASC,
+  CASE WHEN $6::text = 'name' AND $7::boolean THEN name END DESC,
+  CASE WHEN $6::text = 'email' AND NOT $7::boolean THEN email END ASC,
+  CASE WHEN $6::text = 'email' AND $7::boolean THEN email END DESC,
+  CASE WHEN $6::text = 'date_of_birth' AND NOT $7::boolean THEN date_of_birth END ASC,
+  CASE WHEN $6::text = 'date_of_birth' AND $7::boolean THEN date_of_birth END DESC,
+  CASE WHEN $7::boolean THEN id END DESC,
+  id ASC
+`
+
+type FilterAuthorsParams struct {
+	NamePrefix  sql.NullString `json:"name_prefix"`
+	EmailDomain sql.NullString `json:"email_domain"`
+	HasBio      sql.NullBool   `json:"has_bio"`
+	BornAfter   sql.NullTime   `json:"born_after"`
+	BornBefore  sql.NullTime   `json:"born_before"`
+	SortField   string         `json:"sort_field"`
+	SortDesc    bool           `json:"sort_desc"`
+}
+
+func (q *Queries) FilterAuthors(ctx context.Context, arg FilterAuthorsParams) ([]Author, error) {
+	rows, err := q.query(ctx, q.filterAuthorsStmt, FilterAuthors,
+		arg.NamePrefix,
+		arg.EmailDomain,
+		arg.HasBio,
+		arg.BornAfter,
+		arg.BornBefore,
+		arg.SortField,
+		arg.SortDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Author{}
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Bio,
+			&i.Email,
+			&i.DateOfBirth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetAuthor = `-- name: GetAuthor :one
 SELECT id, name, bio, email, date_of_birth FROM authors
 WHERE id = $1
@@ -137,6 +204,82 @@ func (q *Queries) ListAuthors(ctx context.Context) ([]Author, error) {
 	return items, nil
 }
 
+const ListAuthorsByIDs = `-- name: ListAuthorsByIDs :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE id = ANY($1::int[])
+ORDER BY name
+`
+
+func (q *Queries) ListAuthorsByIDs(ctx context.Context, ids []int32) ([]Author, error) {
+	rows, err := q.query(ctx, q.listAuthorsByIDsStmt, ListAuthorsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Author{}
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Bio,
+			&i.Email,
+			&i.DateOfBirth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SearchAuthors = `-- name: SearchAuthors :many
+SELECT id, name, bio, email, date_of_birth FROM authors
+WHERE search @@ websearch_to_tsquery('english', $1)
+ORDER BY ts_rank(search, websearch_to_tsquery('english', $1)) DESC, id
+LIMIT $2
+`
+
+type SearchAuthorsParams struct {
+	Query string `json:"query"`
+	Limit int32  `json:"limit"`
+}
+
+func (q *Queries) SearchAuthors(ctx context.Context, arg SearchAuthorsParams) ([]Author, error) {
+	rows, err := q.query(ctx, q.searchAuthorsStmt, SearchAuthors, arg.Query, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Author{}
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Bio,
+			&i.Email,
+			&i.DateOfBirth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const UpdateAuthor = `-- name: UpdateAuthor :exec
 UPDATE authors
 SET name = $2,
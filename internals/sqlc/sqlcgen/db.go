@@ -24,21 +24,63 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.addBookAuthorStmt, err = db.PrepareContext(ctx, AddBookAuthor); err != nil {
+		return nil, fmt.Errorf("error preparing query AddBookAuthor: %w", err)
+	}
 	if q.createAuthorStmt, err = db.PrepareContext(ctx, CreateAuthor); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAuthor: %w", err)
 	}
+	if q.createBookStmt, err = db.PrepareContext(ctx, CreateBook); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateBook: %w", err)
+	}
+	if q.createPublisherStmt, err = db.PrepareContext(ctx, CreatePublisher); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePublisher: %w", err)
+	}
 	if q.deleteAuthorStmt, err = db.PrepareContext(ctx, DeleteAuthor); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAuthor: %w", err)
 	}
+	if q.deleteBookStmt, err = db.PrepareContext(ctx, DeleteBook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteBook: %w", err)
+	}
+	if q.deletePublisherStmt, err = db.PrepareContext(ctx, DeletePublisher); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePublisher: %w", err)
+	}
+	if q.filterAuthorsStmt, err = db.PrepareContext(ctx, FilterAuthors); err != nil {
+		return nil, fmt.Errorf("error preparing query FilterAuthors: %w", err)
+	}
 	if q.getAuthorStmt, err = db.PrepareContext(ctx, GetAuthor); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAuthor: %w", err)
 	}
 	if q.getAuthorsByBirthdateRangeStmt, err = db.PrepareContext(ctx, GetAuthorsByBirthdateRange); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAuthorsByBirthdateRange: %w", err)
 	}
+	if q.getBookWithPublisherStmt, err = db.PrepareContext(ctx, GetBookWithPublisher); err != nil {
+		return nil, fmt.Errorf("error preparing query GetBookWithPublisher: %w", err)
+	}
+	if q.getPublisherStmt, err = db.PrepareContext(ctx, GetPublisher); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPublisher: %w", err)
+	}
 	if q.listAuthorsStmt, err = db.PrepareContext(ctx, ListAuthors); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAuthors: %w", err)
 	}
+	if q.listAuthorsByBookStmt, err = db.PrepareContext(ctx, ListAuthorsByBook); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuthorsByBook: %w", err)
+	}
+	if q.listAuthorsByIDsStmt, err = db.PrepareContext(ctx, ListAuthorsByIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuthorsByIDs: %w", err)
+	}
+	if q.listAuthorsWithBooksStmt, err = db.PrepareContext(ctx, ListAuthorsWithBooks); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuthorsWithBooks: %w", err)
+	}
+	if q.listBooksByAuthorStmt, err = db.PrepareContext(ctx, ListBooksByAuthor); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBooksByAuthor: %w", err)
+	}
+	if q.listBooksByPublisherStmt, err = db.PrepareContext(ctx, ListBooksByPublisher); err != nil {
+		return nil, fmt.Errorf("error preparing query ListBooksByPublisher: %w", err)
+	}
+	if q.searchAuthorsStmt, err = db.PrepareContext(ctx, SearchAuthors); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchAuthors: %w", err)
+	}
 	if q.updateAuthorStmt, err = db.PrepareContext(ctx, UpdateAuthor); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAuthor: %w", err)
 	}
@@ -47,16 +89,46 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.addBookAuthorStmt != nil {
+		if cerr := q.addBookAuthorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addBookAuthorStmt: %w", cerr)
+		}
+	}
 	if q.createAuthorStmt != nil {
 		if cerr := q.createAuthorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAuthorStmt: %w", cerr)
 		}
 	}
+	if q.createBookStmt != nil {
+		if cerr := q.createBookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createBookStmt: %w", cerr)
+		}
+	}
+	if q.createPublisherStmt != nil {
+		if cerr := q.createPublisherStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPublisherStmt: %w", cerr)
+		}
+	}
 	if q.deleteAuthorStmt != nil {
 		if cerr := q.deleteAuthorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAuthorStmt: %w", cerr)
 		}
 	}
+	if q.deleteBookStmt != nil {
+		if cerr := q.deleteBookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteBookStmt: %w", cerr)
+		}
+	}
+	if q.deletePublisherStmt != nil {
+		if cerr := q.deletePublisherStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePublisherStmt: %w", cerr)
+		}
+	}
+	if q.filterAuthorsStmt != nil {
+		if cerr := q.filterAuthorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing filterAuthorsStmt: %w", cerr)
+		}
+	}
 	if q.getAuthorStmt != nil {
 		if cerr := q.getAuthorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAuthorStmt: %w", cerr)
@@ -67,11 +139,51 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getAuthorsByBirthdateRangeStmt: %w", cerr)
 		}
 	}
+	if q.getBookWithPublisherStmt != nil {
+		if cerr := q.getBookWithPublisherStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getBookWithPublisherStmt: %w", cerr)
+		}
+	}
+	if q.getPublisherStmt != nil {
+		if cerr := q.getPublisherStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPublisherStmt: %w", cerr)
+		}
+	}
 	if q.listAuthorsStmt != nil {
 		if cerr := q.listAuthorsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAuthorsStmt: %w", cerr)
 		}
 	}
+	if q.listAuthorsByBookStmt != nil {
+		if cerr := q.listAuthorsByBookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuthorsByBookStmt: %w", cerr)
+		}
+	}
+	if q.listAuthorsByIDsStmt != nil {
+		if cerr := q.listAuthorsByIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuthorsByIDsStmt: %w", cerr)
+		}
+	}
+	if q.listAuthorsWithBooksStmt != nil {
+		if cerr := q.listAuthorsWithBooksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuthorsWithBooksStmt: %w", cerr)
+		}
+	}
+	if q.listBooksByAuthorStmt != nil {
+		if cerr := q.listBooksByAuthorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBooksByAuthorStmt: %w", cerr)
+		}
+	}
+	if q.listBooksByPublisherStmt != nil {
+		if cerr := q.listBooksByPublisherStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listBooksByPublisherStmt: %w", cerr)
+		}
+	}
+	if q.searchAuthorsStmt != nil {
+		if cerr := q.searchAuthorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchAuthorsStmt: %w", cerr)
+		}
+	}
 	if q.updateAuthorStmt != nil {
 		if cerr := q.updateAuthorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateAuthorStmt: %w", cerr)
@@ -116,11 +228,25 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 type Queries struct {
 	db                             DBTX
 	tx                             *sql.Tx
+	addBookAuthorStmt              *sql.Stmt
 	createAuthorStmt               *sql.Stmt
+	createBookStmt                 *sql.Stmt
+	createPublisherStmt            *sql.Stmt
 	deleteAuthorStmt               *sql.Stmt
+	deleteBookStmt                 *sql.Stmt
+	deletePublisherStmt            *sql.Stmt
+	filterAuthorsStmt              *sql.Stmt
 	getAuthorStmt                  *sql.Stmt
 	getAuthorsByBirthdateRangeStmt *sql.Stmt
+	getBookWithPublisherStmt       *sql.Stmt
+	getPublisherStmt               *sql.Stmt
 	listAuthorsStmt                *sql.Stmt
+	listAuthorsByBookStmt          *sql.Stmt
+	listAuthorsByIDsStmt           *sql.Stmt
+	listAuthorsWithBooksStmt       *sql.Stmt
+	listBooksByAuthorStmt          *sql.Stmt
+	listBooksByPublisherStmt       *sql.Stmt
+	searchAuthorsStmt              *sql.Stmt
 	updateAuthorStmt               *sql.Stmt
 }
 
@@ -128,11 +254,25 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db:                             tx,
 		tx:                             tx,
+		addBookAuthorStmt:              q.addBookAuthorStmt,
 		createAuthorStmt:               q.createAuthorStmt,
+		createBookStmt:                 q.createBookStmt,
+		createPublisherStmt:            q.createPublisherStmt,
 		deleteAuthorStmt:               q.deleteAuthorStmt,
+		deleteBookStmt:                 q.deleteBookStmt,
+		deletePublisherStmt:            q.deletePublisherStmt,
+		filterAuthorsStmt:              q.filterAuthorsStmt,
 		getAuthorStmt:                  q.getAuthorStmt,
 		getAuthorsByBirthdateRangeStmt: q.getAuthorsByBirthdateRangeStmt,
+		getBookWithPublisherStmt:       q.getBookWithPublisherStmt,
+		getPublisherStmt:               q.getPublisherStmt,
 		listAuthorsStmt:                q.listAuthorsStmt,
+		listAuthorsByBookStmt:          q.listAuthorsByBookStmt,
+		listAuthorsByIDsStmt:           q.listAuthorsByIDsStmt,
+		listAuthorsWithBooksStmt:       q.listAuthorsWithBooksStmt,
+		listBooksByAuthorStmt:          q.listBooksByAuthorStmt,
+		listBooksByPublisherStmt:       q.listBooksByPublisherStmt,
+		searchAuthorsStmt:              q.searchAuthorsStmt,
 		updateAuthorStmt:               q.updateAuthorStmt,
 	}
 }
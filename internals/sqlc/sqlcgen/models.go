@@ -15,3 +15,22 @@ type Author struct {
 	Email       string         `json:"email"`
 	DateOfBirth sql.NullTime   `json:"date_of_birth"`
 }
+
+type Book struct {
+	ID          int32        `json:"id"`
+	PublisherID int32        `json:"publisher_id"`
+	Title       string       `json:"title"`
+	Isbn        string       `json:"isbn"`
+	PublishedOn sql.NullTime `json:"published_on"`
+}
+
+type BookAuthor struct {
+	BookID   int32 `json:"book_id"`
+	AuthorID int32 `json:"author_id"`
+}
+
+type Publisher struct {
+	ID      int32          `json:"id"`
+	Name    string         `json:"name"`
+	Country sql.NullString `json:"country"`
+}
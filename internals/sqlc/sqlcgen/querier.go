@@ -9,11 +9,25 @@ import (
 )
 
 type Querier interface {
+	AddBookAuthor(ctx context.Context, arg AddBookAuthorParams) error
 	CreateAuthor(ctx context.Context, arg CreateAuthorParams) (int32, error)
+	CreateBook(ctx context.Context, arg CreateBookParams) (int32, error)
+	CreatePublisher(ctx context.Context, arg CreatePublisherParams) (int32, error)
 	DeleteAuthor(ctx context.Context, id int32) error
+	DeleteBook(ctx context.Context, id int32) error
+	DeletePublisher(ctx context.Context, id int32) error
+	FilterAuthors(ctx context.Context, arg FilterAuthorsParams) ([]Author, error)
 	GetAuthor(ctx context.Context, id int32) (Author, error)
 	GetAuthorsByBirthdateRange(ctx context.Context, arg GetAuthorsByBirthdateRangeParams) ([]Author, error)
+	GetBookWithPublisher(ctx context.Context, id int32) (GetBookWithPublisherRow, error)
+	GetPublisher(ctx context.Context, id int32) (Publisher, error)
 	ListAuthors(ctx context.Context) ([]Author, error)
+	ListAuthorsByBook(ctx context.Context, bookID int32) ([]Author, error)
+	ListAuthorsByIDs(ctx context.Context, ids []int32) ([]Author, error)
+	ListAuthorsWithBooks(ctx context.Context, ids []int32) ([]ListAuthorsWithBooksRow, error)
+	ListBooksByAuthor(ctx context.Context, authorID int32) ([]Book, error)
+	ListBooksByPublisher(ctx context.Context, publisherID int32) ([]Book, error)
+	SearchAuthors(ctx context.Context, arg SearchAuthorsParams) ([]Author, error)
 	UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) error
 }
 
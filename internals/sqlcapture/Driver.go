@@ -0,0 +1,22 @@
+package sqlcapture
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlhook"
+)
+
+// NewConnector returns a driver.Connector for use with sql.OpenDB that opens
+// connections through c and records every statement, transaction boundary
+// and round-trip in recorder.
+func NewConnector(c driver.Connector, recorder *Recorder) driver.Connector {
+	return sqlhook.NewConnector(c, func(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		recorder.Record(ctx, Statement{Query: query, Args: values, Duration: time.Since(start), Err: err})
+	})
+}
@@ -0,0 +1,83 @@
+package sqlcapture
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// recorded returns the query and arguments of every recorded statement.
+func recorded(recorder *Recorder) [][]any {
+	var got [][]any
+	for _, s := range recorder.Statements() {
+		got = append(got, append([]any{s.Query}, s.Args...))
+	}
+	return got
+}
+
+func TestConnector(t *testing.T) {
+	recorder := NewRecorder(nil)
+	db := sql.OpenDB(NewConnector(fakeConnector{}, recorder))
+	defer db.Close()
+	ctx := pkgs.ContextWithOperation(pkgs.ContextWithRequestID(context.Background(), "req-1"), "GetAuthor")
+
+	var id int64
+	if err := db.QueryRowContext(ctx, "SELECT id FROM authors WHERE id = $1", int64(7)).Scan(&id); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	stmt, err := db.PrepareContext(ctx, "DELETE FROM authors WHERE id = $1")
+	if err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+	if _, err := stmt.ExecContext(ctx, int64(8)); err != nil {
+		t.Fatalf("prepared exec failed: %v", err)
+	}
+	stmt.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO authors (name) VALUES ($1)", "Ann"); err != nil {
+		t.Fatalf("exec in transaction failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+
+	want := [][]any{
+		{"SELECT id FROM authors WHERE id = $1", int64(7)},
+		{"PREPARE DELETE FROM authors WHERE id = $1"},
+		{"DELETE FROM authors WHERE id = $1", int64(8)},
+		{"BEGIN"},
+		{"INSERT INTO authors (name) VALUES ($1)", "Ann"},
+		{"COMMIT"},
+		{"BEGIN"},
+		{"ROLLBACK"},
+	}
+	if got := recorded(recorder); !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded = %v, want %v", got, want)
+	}
+	for _, s := range recorder.Statements() {
+		if s.RequestID != "req-1" || s.Operation != "GetAuthor" {
+			t.Errorf("%s recorded with request ID %q and operation %q, want req-1 and GetAuthor", s.Query, s.RequestID, s.Operation)
+		}
+	}
+
+	recorder.Reset()
+	if got := recorder.Statements(); len(got) != 0 {
+		t.Errorf("statements after Reset = %v, want none", got)
+	}
+}
@@ -0,0 +1,50 @@
+package sqlcapture
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlhook"
+	"gorm.io/gorm"
+)
+
+// GormPlugin records the statements GORM sends, with their parameters, and
+// the BEGIN and COMMIT/ROLLBACK of GORM's implicit transactions, in the order
+// they reach the database.
+type GormPlugin struct {
+	recorder *Recorder
+}
+
+// NewGormPlugin creates a plugin for use with gorm.DB.Use.
+func NewGormPlugin(recorder *Recorder) *GormPlugin {
+	return &GormPlugin{recorder: recorder}
+}
+
+// Name implements gorm.Plugin.
+func (p *GormPlugin) Name() string {
+	return "sqlcapture"
+}
+
+// Initialize implements gorm.Plugin.
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	if err := sqlhook.RegisterStatementCallbacks(db, p.Name(), p.recordStatement); err != nil {
+		return err
+	}
+	return sqlhook.RegisterTransactionCallbacks(db, p.Name(), func(db *gorm.DB, query string, start time.Time) {
+		p.recorder.Record(db.Statement.Context, Statement{Query: query, Duration: time.Since(start)})
+	})
+}
+
+func (p *GormPlugin) recordStatement(db *gorm.DB, start time.Time) {
+	// Finding no row is reported by First and friends, not by the database
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	p.recorder.Record(db.Statement.Context, Statement{
+		Query:    db.Statement.SQL.String(),
+		Args:     append([]any(nil), db.Statement.Vars...),
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
@@ -0,0 +1,63 @@
+package sqlcapture
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type author struct {
+	ID   int64
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	sqlDB := sql.OpenDB(fakeConnector{})
+	defer sqlDB.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open failed: %v", err)
+	}
+	recorder := NewRecorder(nil)
+	if err := gormDB.Use(NewGormPlugin(recorder)); err != nil {
+		t.Fatalf("Use failed: %v", err)
+	}
+	ctx := pkgs.ContextWithRequestID(context.Background(), "req-2")
+	db := gormDB.WithContext(ctx)
+
+	// Create runs in an implicit transaction
+	if err := db.Create(&author{Name: "Ann"}).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	var found author
+	if err := db.Where("name = ?", "Bob").Take(&found).Error; err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if err := db.Delete(&author{}, int64(3)).Error; err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	want := [][]any{
+		{"BEGIN"},
+		{`INSERT INTO "authors" ("name") VALUES ($1) RETURNING "id"`, "Ann"},
+		{"COMMIT"},
+		{`SELECT * FROM "authors" WHERE name = $1 LIMIT $2`, "Bob", 1},
+		{"BEGIN"},
+		{`DELETE FROM "authors" WHERE "authors"."id" = $1`, int64(3)},
+		{"COMMIT"},
+	}
+	if got := recorded(recorder); !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded = %v, want %v", got, want)
+	}
+	for _, s := range recorder.Statements() {
+		if s.RequestID != "req-2" {
+			t.Errorf("%s recorded with request ID %q, want req-2", s.Query, s.RequestID)
+		}
+	}
+}
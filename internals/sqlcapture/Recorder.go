@@ -0,0 +1,126 @@
+package sqlcapture
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/pkgs"
+)
+
+// Statement is a single round-trip to the database.
+type Statement struct {
+	Query    string
+	Args     []any
+	Duration time.Duration
+	Err      error
+	// RequestID and Operation are those carried by the context the statement
+	// was sent with, as set by repositories.ContextRepository.
+	RequestID string
+	Operation string
+}
+
+// Recorder collects the statements sent by a database/sql connection or a
+// GORM instance. It is safe for concurrent use.
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	logger     *slog.Logger
+}
+
+// NewRecorder creates an empty recorder. Unless logger is nil, every statement
+// is also logged to it at debug level with the context it was sent with, so
+// that a pkgs.ContextHandler adds the request ID of the repository call.
+func NewRecorder(logger *slog.Logger) *Recorder {
+	return &Recorder{logger: logger}
+}
+
+// Record appends a statement sent with ctx.
+func (r *Recorder) Record(ctx context.Context, s Statement) {
+	s.RequestID = pkgs.RequestIDFromContext(ctx)
+	s.Operation = pkgs.OperationFromContext(ctx)
+	r.mu.Lock()
+	r.statements = append(r.statements, s)
+	r.mu.Unlock()
+
+	if r.logger == nil || !r.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{slog.String("query", Normalize(s.Query)), slog.Duration("duration", s.Duration)}
+	if s.Err != nil {
+		attrs = append(attrs, slog.Any("error", s.Err))
+	}
+	r.logger.LogAttrs(ctx, slog.LevelDebug, "SQL statement", attrs...)
+}
+
+// Reset discards all recorded statements.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+// Statements returns a copy of the statements recorded since the last reset.
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+var (
+	commentPattern    = regexp.MustCompile(`(?m)--.*$`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Normalize strips comments, such as the "-- name:" header sqlc adds, and
+// collapses whitespace so statements from both libraries can be compared.
+func Normalize(query string) string {
+	query = commentPattern.ReplaceAllString(query, "")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(query, " "))
+}
+
+// DistinctQueries returns the normalized statement texts in the order they
+// were first seen.
+func DistinctQueries(statements []Statement) []string {
+	seen := map[string]bool{}
+	var queries []string
+	for _, s := range statements {
+		query := Normalize(s.Query)
+		if !seen[query] {
+			seen[query] = true
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+// FirstStatements returns the first statement recorded for each normalized
+// statement text.
+func FirstStatements(statements []Statement) map[string]Statement {
+	first := map[string]Statement{}
+	for _, s := range statements {
+		query := Normalize(s.Query)
+		if _, ok := first[query]; !ok {
+			first[query] = s
+		}
+	}
+	return first
+}
+
+// Difference returns the queries in a that are not in b.
+func Difference(a, b []string) []string {
+	inB := map[string]bool{}
+	for _, query := range b {
+		inB[query] = true
+	}
+	var diff []string
+	for _, query := range a {
+		if !inB[query] {
+			diff = append(diff, query)
+		}
+	}
+	return diff
+}
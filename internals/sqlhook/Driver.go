@@ -0,0 +1,215 @@
+// Package sqlhook calls a hook for every statement and transaction boundary
+// sent through a database/sql connector or a GORM instance. It is shared by
+// the packages that instrument the benchmark connections, such as sqlcapture
+// and tracing.
+package sqlhook
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// Hook is called after a statement completes. query is the statement text,
+// prefixed with "PREPARE " for prepared statements, or BEGIN, COMMIT or
+// ROLLBACK; ctx is the context the statement was sent with, and start the
+// time it was sent.
+type Hook func(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error)
+
+// connector wraps a database/sql connector so every statement, transaction
+// boundary and round-trip is passed to a hook.
+type connector struct {
+	connector driver.Connector
+	hook      Hook
+}
+
+// NewConnector returns a driver.Connector for use with sql.OpenDB that opens
+// connections through c and calls hook for every statement they send.
+func NewConnector(c driver.Connector, hook Hook) driver.Connector {
+	return &connector{connector: c, hook: hook}
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hookConn{Conn: conn, connector: c}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// call calls the hook unless the driver asked database/sql to fall back to
+// another code path, in which case the statement is reported there.
+func (c *connector) call(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	c.hook(ctx, query, args, start, err)
+}
+
+type hookConn struct {
+	driver.Conn
+	connector *connector
+}
+
+func (c *hookConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *hookConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	c.connector.call(ctx, "PREPARE "+query, nil, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &hookStmt{Stmt: stmt, query: query, connector: c.connector}, nil
+}
+
+func (c *hookConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *hookConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var tx driver.Tx
+	var err error
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = bc.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	c.connector.call(ctx, "BEGIN", nil, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &hookTx{Tx: tx, ctx: ctx, connector: c.connector}, nil
+}
+
+func (c *hookConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := ec.ExecContext(ctx, query, args)
+	c.connector.call(ctx, query, args, start, err)
+	return result, err
+}
+
+func (c *hookConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.connector.call(ctx, query, args, start, err)
+	return rows, err
+}
+
+func (c *hookConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *hookConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *hookConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *hookConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// hookTx keeps the context of BeginTx, as Commit and Rollback take none, so
+// that they are reported with the context of the transaction.
+type hookTx struct {
+	driver.Tx
+	ctx       context.Context
+	connector *connector
+}
+
+func (t *hookTx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.connector.call(t.ctx, "COMMIT", nil, start, err)
+	return err
+}
+
+func (t *hookTx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.connector.call(t.ctx, "ROLLBACK", nil, start, err)
+	return err
+}
+
+type hookStmt struct {
+	driver.Stmt
+	query     string
+	connector *connector
+}
+
+func (s *hookStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValuesToValues(args))
+	}
+	s.connector.call(ctx, s.query, args, start, err)
+	return result, err
+}
+
+func (s *hookStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValuesToValues(args))
+	}
+	s.connector.call(ctx, s.query, args, start, err)
+	return rows, err
+}
+
+func (s *hookStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
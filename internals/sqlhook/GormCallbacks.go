@@ -0,0 +1,129 @@
+package sqlhook
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransactionHook is called with BEGIN, COMMIT or ROLLBACK once a boundary of
+// an implicit GORM transaction completes, and the time it started.
+type TransactionHook func(db *gorm.DB, query string, start time.Time)
+
+// StatementHook is called once a statement GORM sent completes, with the
+// time it started; db.Statement holds its SQL, variables and error.
+type StatementHook func(db *gorm.DB, start time.Time)
+
+// Callback stands in for GORM's unexported callback type.
+type Callback interface {
+	Register(string, func(*gorm.DB)) error
+}
+
+// CallbackProcessor stands in for GORM's unexported processor type, so that
+// callbacks can be registered on any of db.Callback().Create(), Query() and
+// so on.
+type CallbackProcessor[C Callback] interface {
+	Before(string) C
+	After(string) C
+}
+
+// RegisterStatementCallbacks calls hook for every statement sent by the
+// create, query, update, delete, row and raw processors of db. name prefixes
+// the registered callbacks and must be unique per gorm.DB.
+func RegisterStatementCallbacks(db *gorm.DB, name string, hook StatementHook) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		registerStatementCallbacks(callbacks.Create(), name, "gorm:create", hook),
+		registerStatementCallbacks(callbacks.Query(), name, "gorm:query", hook),
+		registerStatementCallbacks(callbacks.Update(), name, "gorm:update", hook),
+		registerStatementCallbacks(callbacks.Delete(), name, "gorm:delete", hook),
+		registerStatementCallbacks(callbacks.Row(), name, "gorm:row", hook),
+		registerStatementCallbacks(callbacks.Raw(), name, "gorm:raw", hook),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerStatementCallbacks calls hook for the statement sent by the step
+// named statement of a GORM processor.
+func registerStatementCallbacks[C Callback, P CallbackProcessor[C]](processor P, name, statement string, hook StatementHook) error {
+	step := statement[len("gorm:"):]
+	startedAtKey := name + ":statement_started_at"
+	start := func(db *gorm.DB) {
+		db.InstanceSet(startedAtKey, time.Now())
+	}
+	call := func(db *gorm.DB) {
+		startedAt, ok := db.InstanceGet(startedAtKey)
+		if !ok || db.Statement.SQL.Len() == 0 {
+			return
+		}
+		hook(db, startedAt.(time.Time))
+	}
+
+	if err := processor.Before(statement).Register(name+":before_"+step, start); err != nil {
+		return err
+	}
+	return processor.After(statement).Register(name+":after_"+step, call)
+}
+
+// RegisterTransactionCallbacks calls hook for the BEGIN and COMMIT/ROLLBACK
+// of the implicit transactions GORM opens around creates, updates and
+// deletes. name prefixes the registered callbacks and must be unique per
+// gorm.DB, such as the name of the plugin registering them.
+func RegisterTransactionCallbacks(db *gorm.DB, name string, hook TransactionHook) error {
+	callbacks := db.Callback()
+	// GORM inserts a callback registered with Before directly in front of its
+	// target, so the "after begin" hook is placed in front of the step that
+	// follows gorm:begin_transaction in each processor.
+	for _, err := range []error{
+		registerTransactionCallbacks(callbacks.Create(), name, "gorm:before_create", hook),
+		registerTransactionCallbacks(callbacks.Update(), name, "gorm:setup_reflect_value", hook),
+		registerTransactionCallbacks(callbacks.Delete(), name, "gorm:before_delete", hook),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerTransactionCallbacks[C Callback, P CallbackProcessor[C]](processor P, name, afterBegin string, hook TransactionHook) error {
+	startedAtKey := name + ":transaction_started_at"
+	start := func(db *gorm.DB) {
+		db.InstanceSet(startedAtKey, time.Now())
+	}
+	call := func(db *gorm.DB, query string) {
+		if _, ok := db.InstanceGet("gorm:started_transaction"); !ok {
+			return
+		}
+		startedAt, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		hook(db, query, startedAt.(time.Time))
+	}
+	begin := func(db *gorm.DB) {
+		call(db, "BEGIN")
+	}
+	commit := func(db *gorm.DB) {
+		if db.Error != nil {
+			call(db, "ROLLBACK")
+			return
+		}
+		call(db, "COMMIT")
+	}
+
+	if err := processor.Before("gorm:begin_transaction").Register(name+":before_begin", start); err != nil {
+		return err
+	}
+	if err := processor.Before(afterBegin).Register(name+":after_begin", begin); err != nil {
+		return err
+	}
+	if err := processor.Before("gorm:commit_or_rollback_transaction").Register(name+":before_commit", start); err != nil {
+		return err
+	}
+	return processor.After("gorm:commit_or_rollback_transaction").Register(name+":after_commit", commit)
+}
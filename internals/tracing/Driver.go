@@ -0,0 +1,21 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlhook"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewConnector returns a driver.Connector for use with sql.OpenDB that opens
+// connections through c and turns every statement and transaction boundary
+// into a span of a tracer of provider. database is reported as db.namespace.
+// Commit and Rollback spans share the parent of their transaction.
+func NewConnector(c driver.Connector, provider trace.TracerProvider, database string) driver.Connector {
+	tracer := provider.Tracer(InstrumentationName)
+	return sqlhook.NewConnector(c, func(ctx context.Context, query string, _ []driver.NamedValue, start time.Time, err error) {
+		recordStatement(ctx, tracer, database, query, start, err)
+	})
+}
@@ -0,0 +1,254 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FileExporter is a span exporter appending every batch of spans to a file
+// as one line of OTLP/JSON, so traces can be inspected or replayed into a
+// collector without running one during the benchmarks.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter opens or creates the file at path for appending.
+func NewFileExporter(path string) (*FileExporter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the %s exporter needs a file", ExporterOTLPFile)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	return &FileExporter{file: file}, nil
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *FileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	line, err := json.Marshal(tracesData{ResourceSpans: toResourceSpans(spans)})
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return os.ErrClosed
+	}
+	_, err = e.file.Write(append(line, '\n'))
+	return err
+}
+
+// Shutdown implements sdktrace.SpanExporter and closes the file.
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+// The types below follow the JSON mapping of the OTLP TracesData message:
+// lowerCamelCase fields, hex IDs, enums as numbers and 64-bit integers as
+// strings.
+
+type tracesData struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   otlpResource `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+	SchemaURL  string       `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope     scope      `json:"scope"`
+	Spans     []otlpSpan `json:"spans"`
+	SchemaURL string     `json:"schemaUrl,omitempty"`
+}
+
+type scope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	TraceState        string     `json:"traceState,omitempty"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Events            []event    `json:"events,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type status struct {
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue `json:"arrayValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+// toResourceSpans groups spans by resource and instrumentation scope,
+// keeping the order in which they ended.
+func toResourceSpans(spans []sdktrace.ReadOnlySpan) []resourceSpans {
+	type scopeKey struct {
+		resource *resource.Resource
+		scope    instrumentation.Scope
+	}
+	resourceIndex := map[*resource.Resource]int{}
+	scopeIndex := map[scopeKey]int{}
+
+	var result []resourceSpans
+	for _, span := range spans {
+		res := span.Resource()
+		ri, ok := resourceIndex[res]
+		if !ok {
+			ri = len(result)
+			resourceIndex[res] = ri
+			result = append(result, resourceSpans{
+				Resource:  otlpResource{Attributes: toKeyValues(res.Attributes())},
+				SchemaURL: res.SchemaURL(),
+			})
+		}
+		key := scopeKey{resource: res, scope: span.InstrumentationScope()}
+		si, ok := scopeIndex[key]
+		if !ok {
+			si = len(result[ri].ScopeSpans)
+			scopeIndex[key] = si
+			result[ri].ScopeSpans = append(result[ri].ScopeSpans, scopeSpans{
+				Scope:     scope{Name: key.scope.Name, Version: key.scope.Version},
+				SchemaURL: key.scope.SchemaURL,
+			})
+		}
+		result[ri].ScopeSpans[si].Spans = append(result[ri].ScopeSpans[si].Spans, toSpan(span))
+	}
+	return result
+}
+
+func toSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	sc := span.SpanContext()
+	s := otlpSpan{
+		TraceID:    sc.TraceID().String(),
+		SpanID:     sc.SpanID().String(),
+		TraceState: sc.TraceState().String(),
+		Name:       span.Name(),
+		// trace.SpanKind uses the numbering of the OTLP enum
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+		Attributes:        toKeyValues(span.Attributes()),
+		Status:            status{Message: span.Status().Description},
+	}
+	if parent := span.Parent(); parent.HasSpanID() {
+		s.ParentSpanID = parent.SpanID().String()
+	}
+	for _, e := range span.Events() {
+		s.Events = append(s.Events, event{
+			TimeUnixNano: strconv.FormatInt(e.Time.UnixNano(), 10),
+			Name:         e.Name,
+			Attributes:   toKeyValues(e.Attributes),
+		})
+	}
+	// Unlike codes.Code, OTLP numbers OK before ERROR
+	switch span.Status().Code {
+	case codes.Ok:
+		s.Status.Code = 1
+	case codes.Error:
+		s.Status.Code = 2
+	}
+	return s
+}
+
+func toKeyValues(attrs []attribute.KeyValue) []keyValue {
+	values := make([]keyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		values = append(values, keyValue{Key: string(attr.Key), Value: toAnyValue(attr.Value)})
+	}
+	return values
+}
+
+func toAnyValue(v attribute.Value) anyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return anyValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return anyValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return anyValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		values := []anyValue{}
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, toAnyValue(attribute.BoolValue(b)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		values := []anyValue{}
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, toAnyValue(attribute.Int64Value(i)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		values := []anyValue{}
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, toAnyValue(attribute.Float64Value(f)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		values := []anyValue{}
+		for _, s := range v.AsStringSlice() {
+			values = append(values, toAnyValue(attribute.StringValue(s)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	default:
+		s := v.Emit()
+		return anyValue{StringValue: &s}
+	}
+}
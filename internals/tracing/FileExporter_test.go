@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("NewFileExporter failed: %v", err)
+	}
+	res := resource.NewWithAttributes("https://example.com/schema", attribute.String("service.name", "bench"))
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+
+	ctx, parent := provider.Tracer("repositories", trace.WithInstrumentationVersion("1.0")).Start(context.Background(), "GetAuthor")
+	_, child := provider.Tracer("statements").Start(ctx, "SELECT",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int64("rows", 42),
+			attribute.Bool("cached", false),
+			attribute.Float64("ratio", 0.5),
+			attribute.StringSlice("tables", []string{"authors", "books"}),
+		))
+	child.RecordError(errors.New("boom"))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	_, sibling := provider.Tracer("repositories", trace.WithInstrumentationVersion("1.0")).Start(ctx, "ListAuthors")
+	sibling.End()
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+
+	// Shutdown exports the spans as one batch and closes the file
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want one per batch", len(lines))
+	}
+	// Field names, enums and 64-bit integers as OTLP/JSON spells them
+	for _, want := range []string{`"resourceSpans":`, `"scopeSpans":`, `"intValue":"42"`, `"kind":3`, `"code":2`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("line does not contain %s: %s", want, lines[0])
+		}
+	}
+
+	var traces tracesData
+	if err := json.Unmarshal([]byte(lines[0]), &traces); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(traces.ResourceSpans) != 1 {
+		t.Fatalf("got %d resources, want 1", len(traces.ResourceSpans))
+	}
+	rs := traces.ResourceSpans[0]
+	if rs.SchemaURL != "https://example.com/schema" {
+		t.Errorf("schema URL = %q", rs.SchemaURL)
+	}
+	if got := attributeValues(rs.Resource.Attributes)["service.name"]; got != "bench" {
+		t.Errorf("service.name = %q, want bench", got)
+	}
+
+	// Spans are grouped by scope, in the order the scopes first ended a span
+	if len(rs.ScopeSpans) != 2 {
+		t.Fatalf("got %d scopes, want 2", len(rs.ScopeSpans))
+	}
+	statements, repositories := rs.ScopeSpans[0], rs.ScopeSpans[1]
+	if statements.Scope != (scope{Name: "statements"}) || repositories.Scope != (scope{Name: "repositories", Version: "1.0"}) {
+		t.Fatalf("scopes = %+v and %+v", statements.Scope, repositories.Scope)
+	}
+	if len(statements.Spans) != 1 || len(repositories.Spans) != 2 {
+		t.Fatalf("got %d and %d spans, want 1 and 2", len(statements.Spans), len(repositories.Spans))
+	}
+
+	query, list, get := statements.Spans[0], repositories.Spans[0], repositories.Spans[1]
+	if list.Name != "ListAuthors" || get.Name != "GetAuthor" {
+		t.Errorf("spans = %s and %s, want ListAuthors and GetAuthor", list.Name, get.Name)
+	}
+	wantIDs := parent.SpanContext()
+	if get.TraceID != wantIDs.TraceID().String() || get.SpanID != wantIDs.SpanID().String() || get.ParentSpanID != "" {
+		t.Errorf("GetAuthor IDs = %s/%s, parent %q, want %s/%s and no parent",
+			get.TraceID, get.SpanID, get.ParentSpanID, wantIDs.TraceID(), wantIDs.SpanID())
+	}
+	if query.TraceID != get.TraceID || query.ParentSpanID != get.SpanID {
+		t.Errorf("SELECT trace %s, parent %s, want a child of GetAuthor", query.TraceID, query.ParentSpanID)
+	}
+
+	// trace.SpanKind shares the OTLP numbering, codes.Code does not
+	if query.Kind != 3 || get.Kind != 1 {
+		t.Errorf("kinds = %d and %d, want 3 (client) and 1 (internal)", query.Kind, get.Kind)
+	}
+	if query.Status != (status{Code: 2, Message: "boom"}) || get.Status != (status{Code: 1}) || list.Status != (status{}) {
+		t.Errorf("statuses = %+v, %+v and %+v, want error, ok and unset", query.Status, get.Status, list.Status)
+	}
+
+	start, err1 := strconv.ParseInt(query.StartTimeUnixNano, 10, 64)
+	end, err2 := strconv.ParseInt(query.EndTimeUnixNano, 10, 64)
+	if err1 != nil || err2 != nil || start <= 0 || end < start {
+		t.Errorf("times = %q to %q, want increasing Unix nanoseconds", query.StartTimeUnixNano, query.EndTimeUnixNano)
+	}
+	if len(query.Events) != 1 || query.Events[0].Name != "exception" {
+		t.Errorf("events = %+v, want the recorded error", query.Events)
+	}
+
+	values := attributeValues(query.Attributes)
+	for key, want := range map[string]string{"rows": "int:42", "cached": "bool:false", "ratio": "double:0.5", "tables": "array:[authors books]"} {
+		if values[key] != want {
+			t.Errorf("attribute %s = %q, want %q", key, values[key], want)
+		}
+	}
+}
+
+// attributeValues describes the value of every attribute as type:value, or
+// just the value for strings.
+func attributeValues(attrs []keyValue) map[string]string {
+	values := map[string]string{}
+	for _, attr := range attrs {
+		values[attr.Key] = describe(attr.Value)
+	}
+	return values
+}
+
+func describe(v anyValue) string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return "bool:" + strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return "int:" + *v.IntValue
+	case v.DoubleValue != nil:
+		return "double:" + strconv.FormatFloat(*v.DoubleValue, 'g', -1, 64)
+	case v.ArrayValue != nil:
+		var items []string
+		for _, item := range v.ArrayValue.Values {
+			items = append(items, describe(item))
+		}
+		return "array:[" + strings.Join(items, " ") + "]"
+	default:
+		return ""
+	}
+}
+
+func TestFileExporterAfterShutdown(t *testing.T) {
+	exporter, err := NewFileExporter(filepath.Join(t.TempDir(), "traces.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileExporter failed: %v", err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := exporter.ExportSpans(context.Background(), nil); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("ExportSpans after Shutdown = %v, want %v", err, os.ErrClosed)
+	}
+	if _, err := NewFileExporter(""); err == nil {
+		t.Errorf("NewFileExporter without a path succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlhook"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// GormPlugin turns every statement GORM sends, and the BEGIN and
+// COMMIT/ROLLBACK of its implicit transactions, into a span. Spans are
+// children of the span in the context passed to gorm.DB.WithContext.
+type GormPlugin struct {
+	tracer   trace.Tracer
+	database string
+}
+
+// NewGormPlugin creates a plugin for use with gorm.DB.Use that traces with a
+// tracer of provider. database is reported as db.namespace.
+func NewGormPlugin(provider trace.TracerProvider, database string) *GormPlugin {
+	return &GormPlugin{tracer: provider.Tracer(InstrumentationName), database: database}
+}
+
+// Name implements gorm.Plugin.
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin.
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	if err := sqlhook.RegisterStatementCallbacks(db, p.Name(), p.recordStatement); err != nil {
+		return err
+	}
+	return sqlhook.RegisterTransactionCallbacks(db, p.Name(), func(db *gorm.DB, query string, start time.Time) {
+		recordStatement(db.Statement.Context, p.tracer, p.database, query, start, nil)
+	})
+}
+
+func (p *GormPlugin) recordStatement(db *gorm.DB, start time.Time) {
+	// Finding no row is reported by First and friends, not by the database
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	recordStatement(db.Statement.Context, p.tracer, p.database, db.Statement.SQL.String(), start, err,
+		semconv.DBCollectionName(db.Statement.Table))
+}
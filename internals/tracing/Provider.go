@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters supported by NewTracerProvider.
+const (
+	// ExporterStdout prints spans to stdout as indented JSON.
+	ExporterStdout = "stdout"
+	// ExporterOTLPFile appends spans to a file in the OTLP/JSON format, one
+	// batch per line, as read by the OpenTelemetry Collector's otlpjsonfile
+	// receiver.
+	ExporterOTLPFile = "otlp-file"
+)
+
+// ServiceName is the service.name of the spans of this program.
+const ServiceName = "sqlcVsGorm"
+
+// NewTracerProvider returns a provider batching spans to the named exporter.
+// path is the file written by ExporterOTLPFile. Shutting the provider down
+// flushes the remaining spans and closes the file.
+func NewTracerProvider(exporter, path string) (*sdktrace.TracerProvider, error) {
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case ExporterStdout:
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		spanExporter = stdoutExporter
+	case ExporterOTLPFile:
+		fileExporter, err := NewFileExporter(path)
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = fileExporter
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected %q or %q", exporter, ExporterStdout, ExporterOTLPFile)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	), nil
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of the statement spans.
+const InstrumentationName = "github.com/lordofthemind/sqlcVsGorm_GO/internals/tracing"
+
+// recordStatement records a statement that ran from start until now as a
+// client span, child of the span in ctx. Spans are created once the
+// statement is done so that calls the driver skips leave no span behind.
+func recordStatement(ctx context.Context, tracer trace.Tracer, database, query string, start time.Time, err error, attrs ...attribute.KeyValue) {
+	operation := operationName(query)
+	attrs = append(attrs,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(query),
+	)
+	if database != "" {
+		attrs = append(attrs, semconv.DBNamespace(database))
+	}
+	_, span := tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// operationName returns the first keyword of query, such as SELECT, after
+// the comments sqlc puts in front of its queries.
+func operationName(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return "SQL"
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return "SQL"
+			}
+			query = query[end+2:]
+		default:
+			keyword, _, _ := strings.Cut(query, " ")
+			keyword, _, _ = strings.Cut(keyword, "\n")
+			if keyword == "" {
+				return "SQL"
+			}
+			return strings.ToUpper(keyword)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package tracing
+
+import "testing"
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM authors", "SELECT"},
+		{"select id from authors", "SELECT"},
+		{"INSERT\nINTO authors", "INSERT"},
+		{"  \n\tUPDATE authors SET name = $1", "UPDATE"},
+		{"-- name: GetAuthor :one\nSELECT id, name FROM authors WHERE id = $1 LIMIT 1", "SELECT"},
+		{"-- name: DeleteAuthor :exec\n-- deletes one author\nDELETE FROM authors WHERE id = $1", "DELETE"},
+		{"/* GetAuthor */ SELECT 1", "SELECT"},
+		{"/* multi\nline */\n-- name: ListAuthors :many\nWITH a AS (SELECT 1) SELECT * FROM a", "WITH"},
+		{"BEGIN", "BEGIN"},
+		{"-- name: Unterminated :one", "SQL"},
+		{"/* unterminated", "SQL"},
+		{"", "SQL"},
+		{"   ", "SQL"},
+	}
+	for _, tt := range tests {
+		if got := operationName(tt.query); got != tt.want {
+			t.Errorf("operationName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
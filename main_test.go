@@ -0,0 +1,667 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/cache"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/config"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/repositories/repositorytest"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/roundtrip"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlc/sqlcgen"
+	"github.com/lordofthemind/sqlcVsGorm_GO/internals/sqlcapture"
+	"golang.org/x/exp/rand"
+)
+
+// seedCount is the number of authors seeded for read and update benchmarks.
+const seedCount = 100
+
+// searchSeedCount is the number of authors BenchmarkSearchAuthors searches.
+const searchSeedCount = 10000
+
+// Size of the data set loaded by BenchmarkAuthorsWithBooks. Every book is
+// co-written by two neighbouring authors, so each author has twice
+// booksPerAuthor books.
+const (
+	relationAuthorCount = 50
+	booksPerAuthor      = 3
+)
+
+// namedRepository pairs a repository with the sub-benchmark name it runs under
+// and the counter of its network round-trips.
+type namedRepository struct {
+	name       string
+	repo       repositories.AuthorRepository
+	roundTrips *roundtrip.Counter
+}
+
+var (
+	benchReposOnce sync.Once
+	benchRepos     []namedRepository
+	benchReposErr  error
+)
+
+// benchmarkRepositories opens both repositories once per test binary and skips
+// the test or benchmark when the databases are not reachable.
+func benchmarkRepositories(tb testing.TB) []namedRepository {
+	tb.Helper()
+	benchReposOnce.Do(func() {
+		cfg, err := config.Load("")
+		if err != nil {
+			benchReposErr = err
+			return
+		}
+		sqlcConfig, gormConfig := cfg.RepositoryDatabases()
+		sqlcTrips, gormTrips := roundtrip.NewCounter(), roundtrip.NewCounter()
+		sqlcRepo, _, err := openSQLCRepository(sqlcConfig, connectionOptions{RoundTrips: sqlcTrips})
+		if err != nil {
+			benchReposErr = err
+			return
+		}
+		gormRepo, _, err := openGORMRepository(gormConfig, connectionOptions{RoundTrips: gormTrips})
+		if err != nil {
+			benchReposErr = err
+			return
+		}
+		benchRepos = []namedRepository{
+			{name: "sqlc", repo: sqlcRepo, roundTrips: sqlcTrips},
+			{name: "gorm", repo: gormRepo, roundTrips: gormTrips},
+		}
+	})
+	if benchReposErr != nil {
+		tb.Skipf("database not available: %v", benchReposErr)
+	}
+	return benchRepos
+}
+
+// TestAuthorRepositoryConformance holds both repositories to the suite that
+// MemoryRepository is checked against.
+func TestAuthorRepositoryConformance(t *testing.T) {
+	for _, r := range benchmarkRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			repositorytest.TestAuthorRepository(t, r.repo)
+		})
+	}
+}
+
+// startMeasuring resets the timer and the round-trip counter once the
+// fixtures are in place.
+func startMeasuring(b *testing.B, r namedRepository) {
+	b.Helper()
+	r.roundTrips.Reset()
+	b.ResetTimer()
+}
+
+// reportRoundTrips adds a roundtrips/op metric to the benchmark output.
+func reportRoundTrips(b *testing.B, r namedRepository) {
+	b.Helper()
+	b.StopTimer()
+	b.ReportMetric(float64(r.roundTrips.Count())/float64(b.N), "roundtrips/op")
+}
+
+// newBenchmarkRand returns a fixed-seed generator so runs are comparable.
+func newBenchmarkRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+// seedAuthors creates count authors and removes them when the benchmark ends.
+func seedAuthors(b *testing.B, repo repositories.AuthorRepository, rng *rand.Rand, count int) []int32 {
+	b.Helper()
+	ctx := context.Background()
+	ids := make([]int32, 0, count)
+	for _, f := range newAuthorFixtures(rng, count) {
+		id, err := repo.CreateAuthor(ctx, f.name, f.bio, f.email, f.dateOfBirth)
+		if err != nil {
+			b.Fatalf("failed to seed author: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	b.Cleanup(func() { deleteAuthors(b, repo, ids) })
+	return ids
+}
+
+// deleteAuthors removes the given authors, ignoring rows that are already gone.
+func deleteAuthors(b *testing.B, repo repositories.AuthorRepository, ids []int32) {
+	b.Helper()
+	for _, id := range ids {
+		if err := repo.DeleteAuthor(context.Background(), id); err != nil && err != sql.ErrNoRows {
+			b.Errorf("failed to clean up author %d: %v", id, err)
+		}
+	}
+}
+
+func BenchmarkCreateAuthor(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			fixtures := newAuthorFixtures(newBenchmarkRand(), b.N)
+			ids := make([]int32, 0, b.N)
+			b.Cleanup(func() { deleteAuthors(b, r.repo, ids) })
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				f := fixtures[i]
+				id, err := r.repo.CreateAuthor(ctx, f.name, f.bio, f.email, f.dateOfBirth)
+				if err != nil {
+					b.Fatalf("failed to create author: %v", err)
+				}
+				ids = append(ids, id)
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkGetAuthor(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			ids := seedAuthors(b, r.repo, newBenchmarkRand(), seedCount)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.GetAuthor(ctx, ids[i%len(ids)]); err != nil {
+					b.Fatalf("failed to get author: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkListAuthors(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			seedAuthors(b, r.repo, newBenchmarkRand(), seedCount)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.ListAuthors(ctx); err != nil {
+					b.Fatalf("failed to list authors: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkDeleteAuthor(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			ids := seedAuthors(b, r.repo, newBenchmarkRand(), b.N)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if err := r.repo.DeleteAuthor(ctx, ids[i]); err != nil {
+					b.Fatalf("failed to delete author: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkUpdateAuthor(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			rng := newBenchmarkRand()
+			ids := seedAuthors(b, r.repo, rng, seedCount)
+			fixtures := newAuthorFixtures(rng, b.N)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				f := fixtures[i]
+				if err := r.repo.UpdateAuthor(ctx, ids[i%len(ids)], f.name, f.bio, f.email, f.dateOfBirth); err != nil {
+					b.Fatalf("failed to update author: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkGetAuthorsByBirthdateRange(b *testing.B) {
+	startDate := time.Now().AddDate(-5, 0, 0) // 5 years ago
+	endDate := time.Now()
+
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			seedAuthors(b, r.repo, newBenchmarkRand(), seedCount)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.GetAuthorsByBirthdateRange(ctx, startDate, endDate); err != nil {
+					b.Fatalf("failed to get authors by birthdate range: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkSearchAuthors(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		// Seed once per repository, the table is too large to refill for every b.N
+		seedAuthors(b, r.repo, newBenchmarkRand(), searchSeedCount)
+
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			rng := newBenchmarkRand()
+			queries := make([]string, b.N)
+			for i := range queries {
+				queries[i] = fmt.Sprintf("Bio%d", rng.Intn(1000))
+			}
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.SearchAuthors(ctx, queries[i], 10); err != nil {
+					b.Fatalf("failed to search authors: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+func BenchmarkFilterAuthors(b *testing.B) {
+	hasBio := true
+	filter := repositories.AuthorFilter{
+		NamePrefix:  "Author1",
+		EmailDomain: "example.com",
+		HasBio:      &hasBio,
+		BornAfter:   time.Now().AddDate(-30, 0, 0),
+		BornBefore:  time.Now(),
+		SortBy:      repositories.SortByDateOfBirth,
+		Direction:   repositories.Descending,
+	}
+
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			seedAuthors(b, r.repo, newBenchmarkRand(), seedCount)
+
+			startMeasuring(b, r)
+			for i := 0; i < b.N; i++ {
+				if _, err := r.repo.FilterAuthors(ctx, filter); err != nil {
+					b.Fatalf("failed to filter authors: %v", err)
+				}
+			}
+			reportRoundTrips(b, r)
+		})
+	}
+}
+
+// loadStrategy is one way of loading authors with their books.
+type loadStrategy struct {
+	name string
+	load func(ctx context.Context, authorIDs []int32) ([]repositories.AuthorWithBooks, error)
+}
+
+// relationalRepository holds the author and book repositories of one library
+// on a connection that records its statements, so queries can be counted.
+type relationalRepository struct {
+	name       string
+	authors    repositories.AuthorRepository
+	books      repositories.BookRepository
+	strategies []loadStrategy
+	roundTrips *roundtrip.Counter
+	recorder   *sqlcapture.Recorder
+}
+
+var (
+	relationalReposOnce sync.Once
+	relationalRepos     []relationalRepository
+	relationalReposErr  error
+)
+
+// relationalRepositories opens both libraries with SQL capture enabled, apart
+// from the connections of benchmarkRepositories so the other benchmarks do
+// not pay for the recording.
+func relationalRepositories(tb testing.TB) []relationalRepository {
+	tb.Helper()
+	relationalReposOnce.Do(func() {
+		cfg, err := config.Load("")
+		if err != nil {
+			relationalReposErr = err
+			return
+		}
+		sqlcConfig, gormConfig := cfg.RepositoryDatabases()
+
+		sqlcOpts := connectionOptions{Recorder: sqlcapture.NewRecorder(nil), RoundTrips: roundtrip.NewCounter()}
+		sqlcRepo, sqlDB, err := openSQLCRepository(sqlcConfig, sqlcOpts)
+		if err != nil {
+			relationalReposErr = err
+			return
+		}
+		sqlcBooks := repositories.NewSQLCBookRepository(sqlDB, sqlcgen.New(sqlDB))
+
+		gormOpts := connectionOptions{Recorder: sqlcapture.NewRecorder(nil), RoundTrips: roundtrip.NewCounter()}
+		gormRepo, gormDB, err := openGORMRepository(gormConfig, gormOpts)
+		if err != nil {
+			relationalReposErr = err
+			return
+		}
+		gormBooks := repositories.NewGORMBookRepository(gormDB)
+
+		relationalRepos = []relationalRepository{
+			{
+				name:    "sqlc",
+				authors: sqlcRepo,
+				books:   sqlcBooks,
+				strategies: []loadStrategy{
+					{"naive", sqlcBooks.ListAuthorsWithBooksNaive},
+					{"join", sqlcBooks.ListAuthorsWithBooks},
+				},
+				roundTrips: sqlcOpts.RoundTrips,
+				recorder:   sqlcOpts.Recorder,
+			},
+			{
+				name:    "gorm",
+				authors: gormRepo,
+				books:   gormBooks,
+				strategies: []loadStrategy{
+					{"naive", gormBooks.ListAuthorsWithBooksNaive},
+					{"preload", gormBooks.ListAuthorsWithBooks},
+					{"joins", gormBooks.ListAuthorsWithBooksJoins},
+				},
+				roundTrips: gormOpts.RoundTrips,
+				recorder:   gormOpts.Recorder,
+			},
+		}
+	})
+	if relationalReposErr != nil {
+		tb.Skipf("database not available: %v", relationalReposErr)
+	}
+	return relationalRepos
+}
+
+func TestBookRepositoryConformance(t *testing.T) {
+	for _, r := range relationalRepositories(t) {
+		t.Run(r.name, func(t *testing.T) {
+			repositorytest.TestBookRepository(t, r.authors, r.books)
+		})
+	}
+}
+
+// startCountingQueries resets the timer, the statement recorder and the
+// round-trip counter once the fixtures are in place.
+func startCountingQueries(b *testing.B, r relationalRepository) {
+	b.Helper()
+	r.recorder.Reset()
+	r.roundTrips.Reset()
+	b.ResetTimer()
+}
+
+// reportQueries adds queries/op and roundtrips/op metrics to the benchmark
+// output.
+func reportQueries(b *testing.B, r relationalRepository) {
+	b.Helper()
+	b.StopTimer()
+	b.ReportMetric(float64(len(r.recorder.Statements()))/float64(b.N), "queries/op")
+	b.ReportMetric(float64(r.roundTrips.Count())/float64(b.N), "roundtrips/op")
+}
+
+// bookFixture holds the arguments of a random book.
+type bookFixture struct {
+	title       string
+	isbn        string
+	publishedOn sql.NullTime
+}
+
+// newBookFixtures generates count random books up front so fixture
+// generation is not part of the measured time. ISBNs are unique within a call.
+func newBookFixtures(rng *rand.Rand, count int) []bookFixture {
+	prefix := rng.Uint32()
+	fixtures := make([]bookFixture, count)
+	for i := range fixtures {
+		fixtures[i] = bookFixture{
+			title:       fmt.Sprintf("Book%d", rng.Intn(1000)),
+			isbn:        fmt.Sprintf("978-%010d-%d", prefix, i),
+			publishedOn: sql.NullTime{Time: time.Now().AddDate(-rng.Intn(30), 0, 0), Valid: true},
+		}
+	}
+	return fixtures
+}
+
+// seedPublisher creates a publisher and removes it when the benchmark ends,
+// after the books created later.
+func seedPublisher(b *testing.B, r relationalRepository, rng *rand.Rand) int32 {
+	b.Helper()
+	publisherID, err := r.books.CreatePublisher(context.Background(), fmt.Sprintf("Publisher%d", rng.Uint32()), sql.NullString{String: "NL", Valid: true})
+	if err != nil {
+		b.Fatalf("failed to seed publisher: %v", err)
+	}
+	b.Cleanup(func() {
+		if err := r.books.DeletePublisher(context.Background(), publisherID); err != nil {
+			b.Errorf("failed to clean up publisher %d: %v", publisherID, err)
+		}
+	})
+	return publisherID
+}
+
+// deleteBooks removes the given books, ignoring rows that are already gone.
+func deleteBooks(b *testing.B, repo repositories.BookRepository, ids []int32) {
+	b.Helper()
+	for _, id := range ids {
+		if err := repo.DeleteBook(context.Background(), id); err != nil {
+			b.Errorf("failed to clean up book %d: %v", id, err)
+		}
+	}
+}
+
+// BenchmarkCreateBook creates books co-written by two authors, inserting the
+// book and its book_authors rows in one transaction.
+func BenchmarkCreateBook(b *testing.B) {
+	for _, r := range relationalRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			rng := newBenchmarkRand()
+			publisherID := seedPublisher(b, r, rng)
+			authorIDs := seedAuthors(b, r.authors, rng, 2)
+			fixtures := newBookFixtures(rng, b.N)
+			ids := make([]int32, 0, b.N)
+			b.Cleanup(func() { deleteBooks(b, r.books, ids) })
+
+			startCountingQueries(b, r)
+			for i := 0; i < b.N; i++ {
+				f := fixtures[i]
+				id, err := r.books.CreateBook(ctx, publisherID, f.title, f.isbn, f.publishedOn, authorIDs)
+				if err != nil {
+					b.Fatalf("failed to create book: %v", err)
+				}
+				ids = append(ids, id)
+			}
+			reportQueries(b, r)
+		})
+	}
+}
+
+// BenchmarkGetBook loads books with their publisher and two authors: a
+// sqlc.embed JOIN and an authors query for sqlc, Preload for GORM.
+func BenchmarkGetBook(b *testing.B) {
+	for _, r := range relationalRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ctx := context.Background()
+			rng := newBenchmarkRand()
+			publisherID := seedPublisher(b, r, rng)
+			authorIDs := seedAuthors(b, r.authors, rng, 2)
+			ids := make([]int32, 0, seedCount)
+			b.Cleanup(func() { deleteBooks(b, r.books, ids) })
+			for _, f := range newBookFixtures(rng, seedCount) {
+				id, err := r.books.CreateBook(ctx, publisherID, f.title, f.isbn, f.publishedOn, authorIDs)
+				if err != nil {
+					b.Fatalf("failed to seed book: %v", err)
+				}
+				ids = append(ids, id)
+			}
+
+			startCountingQueries(b, r)
+			for i := 0; i < b.N; i++ {
+				book, err := r.books.GetBook(ctx, ids[i%len(ids)])
+				if err != nil {
+					b.Fatalf("failed to get book: %v", err)
+				}
+				if len(book.Authors) != len(authorIDs) {
+					b.Fatalf("got %d authors, want %d", len(book.Authors), len(authorIDs))
+				}
+			}
+			reportQueries(b, r)
+		})
+	}
+}
+
+// seedAuthorsWithBooks creates a publisher, relationAuthorCount authors and
+// their books, removes them when the benchmark ends and returns the author IDs.
+func seedAuthorsWithBooks(b *testing.B, r relationalRepository, rng *rand.Rand) []int32 {
+	b.Helper()
+	ctx := context.Background()
+
+	publisherID := seedPublisher(b, r, rng)
+	authorIDs := seedAuthors(b, r.authors, rng, relationAuthorCount)
+	var bookIDs []int32
+	b.Cleanup(func() { deleteBooks(b, r.books, bookIDs) })
+	fixtures := newBookFixtures(rng, len(authorIDs)*booksPerAuthor)
+	for i, authorID := range authorIDs {
+		coAuthorID := authorIDs[(i+1)%len(authorIDs)]
+		for _, f := range fixtures[i*booksPerAuthor : (i+1)*booksPerAuthor] {
+			id, err := r.books.CreateBook(ctx, publisherID, f.title, f.isbn, f.publishedOn, []int32{authorID, coAuthorID})
+			if err != nil {
+				b.Fatalf("failed to seed book: %v", err)
+			}
+			bookIDs = append(bookIDs, id)
+		}
+	}
+	return authorIDs
+}
+
+// BenchmarkAuthorsWithBooks compares the strategies for loading authors with
+// their books: one query per author (N+1), a single JOIN (hand-written for
+// sqlc, built with Joins for GORM), and GORM's Preload.
+func BenchmarkAuthorsWithBooks(b *testing.B) {
+	for _, r := range relationalRepositories(b) {
+		for _, s := range r.strategies {
+			b.Run(r.name+"-"+s.name, func(b *testing.B) {
+				ctx := context.Background()
+				ids := seedAuthorsWithBooks(b, r, newBenchmarkRand())
+
+				startCountingQueries(b, r)
+				for i := 0; i < b.N; i++ {
+					authors, err := s.load(ctx, ids)
+					if err != nil {
+						b.Fatalf("failed to load authors with books: %v", err)
+					}
+					if len(authors) != len(ids) {
+						b.Fatalf("loaded %d authors, want %d", len(authors), len(ids))
+					}
+				}
+				reportQueries(b, r)
+			})
+		}
+	}
+}
+
+// reportCacheStats reports the hit ratio of repo and how many calls reached
+// the wrapped repository.
+func reportCacheStats(b *testing.B, repo *repositories.CachingRepository) {
+	b.Helper()
+	stats := repo.Stats()
+	b.ReportMetric(stats.HitRatio(), "hit-ratio")
+	b.ReportMetric(float64(stats.Loads)/float64(b.N), "loads/op")
+}
+
+// BenchmarkCachedGetAuthor reads authors through a CachingRepository backed
+// by LRU caches of decreasing capacity. Ids follow a Zipf distribution, so a
+// few authors are read far more often than the others, as in most workloads.
+func BenchmarkCachedGetAuthor(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ids := seedAuthors(b, r.repo, newBenchmarkRand(), seedCount)
+
+			for _, capacity := range []int{seedCount, seedCount / 4, seedCount / 20} {
+				b.Run(fmt.Sprintf("capacity=%d", capacity), func(b *testing.B) {
+					ctx := context.Background()
+					repo := repositories.NewCachingRepository(r.repo, cache.NewLRUCache(capacity), time.Minute)
+					zipf := rand.NewZipf(newBenchmarkRand(), 1.1, 1, uint64(len(ids)-1))
+
+					startMeasuring(b, r)
+					for i := 0; i < b.N; i++ {
+						if _, err := repo.GetAuthor(ctx, ids[zipf.Uint64()]); err != nil {
+							b.Fatalf("failed to get author: %v", err)
+						}
+					}
+					reportRoundTrips(b, r)
+					reportCacheStats(b, repo)
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkCachedGetAuthorParallel reads a handful of authors from many
+// goroutines with a short TTL, so that entries keep expiring while being
+// read concurrently. loads/op shows how many misses singleflight collapsed.
+func BenchmarkCachedGetAuthorParallel(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			ids := seedAuthors(b, r.repo, newBenchmarkRand(), 5)
+			repo := repositories.NewCachingRepository(r.repo, cache.NewLRUCache(len(ids)), time.Millisecond)
+
+			startMeasuring(b, r)
+			b.RunParallel(func(pb *testing.PB) {
+				ctx := context.Background()
+				for i := 0; pb.Next(); i++ {
+					if _, err := repo.GetAuthor(ctx, ids[i%len(ids)]); err != nil {
+						b.Errorf("failed to get author: %v", err)
+						return
+					}
+				}
+			})
+			reportRoundTrips(b, r)
+			reportCacheStats(b, repo)
+		})
+	}
+}
+
+// BenchmarkCachedListAuthors lists authors through a CachingRepository while
+// updating one every writeEvery calls, each update invalidating the cached list.
+func BenchmarkCachedListAuthors(b *testing.B) {
+	for _, r := range benchmarkRepositories(b) {
+		b.Run(r.name, func(b *testing.B) {
+			rng := newBenchmarkRand()
+			ids := seedAuthors(b, r.repo, rng, seedCount)
+
+			for _, writeEvery := range []int{10, 100, 1000} {
+				b.Run(fmt.Sprintf("write-every=%d", writeEvery), func(b *testing.B) {
+					ctx := context.Background()
+					repo := repositories.NewCachingRepository(r.repo, cache.NewLRUCache(seedCount), time.Minute)
+					fixtures := newAuthorFixtures(rng, b.N/writeEvery+1)
+
+					startMeasuring(b, r)
+					for i := 0; i < b.N; i++ {
+						if i%writeEvery == writeEvery-1 {
+							f := fixtures[i/writeEvery]
+							if err := repo.UpdateAuthor(ctx, ids[i%len(ids)], f.name, f.bio, f.email, f.dateOfBirth); err != nil {
+								b.Fatalf("failed to update author: %v", err)
+							}
+							continue
+						}
+						if _, err := repo.ListAuthors(ctx); err != nil {
+							b.Fatalf("failed to list authors: %v", err)
+						}
+					}
+					reportRoundTrips(b, r)
+					reportCacheStats(b, repo)
+				})
+			}
+		})
+	}
+}
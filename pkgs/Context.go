@@ -0,0 +1,90 @@
+package pkgs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// RequestIDKey is the attribute key of the request ID taken from the context.
+const RequestIDKey = "request_id"
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	operationContextKey
+)
+
+// NewRequestID returns a random 16 character hex ID.
+func NewRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// ContextWithOperation returns a copy of ctx carrying the name of the
+// repository operation being run.
+func ContextWithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationContextKey, operation)
+}
+
+// OperationFromContext returns the operation name carried by ctx, or "".
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationContextKey).(string)
+	return operation
+}
+
+// ContextHandler adds the request ID and operation carried by the context
+// passed to the *Context logging methods to every record, unless the record
+// already has them. Like other record attributes, they are nested in the
+// groups opened with WithGroup.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps next in a ContextHandler.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: next}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" && !hasAttr(record, RequestIDKey) {
+		record.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if operation := OperationFromContext(ctx); operation != "" && !hasAttr(record, OperationKey) {
+		record.AddAttrs(Operation(operation))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func hasAttr(record slog.Record, key string) bool {
+	found := false
+	record.Attrs(func(attr slog.Attr) bool {
+		found = attr.Key == key
+		return !found
+	})
+	return found
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,77 @@
+package pkgs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logJSON logs message through a ContextHandler over a JSON handler and
+// returns the decoded line along with the raw one.
+func logJSON(t *testing.T, ctx context.Context, configure func(*slog.Logger) *slog.Logger, args ...any) (map[string]any, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+	if configure != nil {
+		logger = configure(logger)
+	}
+	logger.InfoContext(ctx, "message", args...)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to decode %q: %v", buf.String(), err)
+	}
+	return line, buf.String()
+}
+
+func TestContextHandler(t *testing.T) {
+	ctx := ContextWithOperation(ContextWithRequestID(context.Background(), "abc123"), "GetAuthor")
+
+	line, _ := logJSON(t, ctx, nil)
+	if line[RequestIDKey] != "abc123" || line[OperationKey] != "GetAuthor" {
+		t.Errorf("logged %v, want request_id abc123 and operation GetAuthor", line)
+	}
+
+	line, _ = logJSON(t, context.Background(), nil)
+	for _, key := range []string{RequestIDKey, OperationKey} {
+		if value, ok := line[key]; ok {
+			t.Errorf("%s = %v without a context value, want none", key, value)
+		}
+	}
+
+	// Attributes set by the caller win over those of the context
+	line, raw := logJSON(t, ctx, nil, Operation("ListAuthors"), slog.String(RequestIDKey, "own"))
+	if line[RequestIDKey] != "own" || line[OperationKey] != "ListAuthors" {
+		t.Errorf("logged %v, want the caller's request_id and operation", line)
+	}
+	for _, key := range []string{RequestIDKey, OperationKey} {
+		if got := strings.Count(raw, `"`+key+`"`); got != 1 {
+			t.Errorf("%s logged %d times in %s, want once", key, got, raw)
+		}
+	}
+
+	// Attributes added with With are kept, and groups nest the context values
+	line, _ = logJSON(t, ctx, func(l *slog.Logger) *slog.Logger {
+		return l.With(Component("benchmark")).WithGroup("call")
+	})
+	if line[ComponentKey] != "benchmark" {
+		t.Errorf("component = %v, want benchmark", line[ComponentKey])
+	}
+	group, _ := line["call"].(map[string]any)
+	if group[RequestIDKey] != "abc123" || group[OperationKey] != "GetAuthor" {
+		t.Errorf("call group = %v, want request_id abc123 and operation GetAuthor", line["call"])
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	first, second := NewRequestID(), NewRequestID()
+	if len(first) != 16 || strings.Trim(first, "0123456789abcdef") != "" {
+		t.Errorf("NewRequestID = %q, want 16 hex characters", first)
+	}
+	if first == second {
+		t.Errorf("NewRequestID returned %q twice", first)
+	}
+}
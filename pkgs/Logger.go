@@ -3,44 +3,98 @@ package pkgs
 import (
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
-	"time"
+	"path/filepath"
 )
 
-// SetUpLogger sets up logging to a file and stdout.
-// It returns the log file for deferred closing and an error if any occurs.
-func SetUpLogger(logFileName string) (*os.File, error) {
-	// Ensure the logs directory exists
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+// Log formats supported by SetUpLogger.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Attribute keys shared by every component that logs benchmark activity.
+const (
+	ComponentKey  = "component"
+	RepositoryKey = "repository"
+	OperationKey  = "operation"
+)
+
+// LoggerConfig describes where and how SetUpLogger writes logs.
+type LoggerConfig struct {
+	// Dir and FileName name the log file. An empty Dir logs to Stdout only.
+	Dir      string
+	FileName string
+	// Rotation controls when the log file is rotated and how long rotated
+	// files are kept.
+	Rotation RotationConfig
+	// Level is the minimum level written.
+	Level slog.Level
+	// Format is FormatText or FormatJSON.
+	Format string
+	// Stdout receives a copy of every line; nil defaults to os.Stdout.
+	Stdout io.Writer
+}
+
+// SetUpLogger returns a logger writing to a rotating file in cfg.Dir and to
+// stdout. It does not touch the global log or slog loggers. The returned
+// closer closes the log file and must be called once logging is done.
+func SetUpLogger(cfg LoggerConfig) (*slog.Logger, io.Closer, error) {
+	stdout := cfg.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
 	}
 
-	// Get the current date and time for the log file prefix
-	currentTime := time.Now().Format("20060102_150405")
-	logFileName = fmt.Sprintf("%s_%s", currentTime, logFileName)
+	var out io.Writer = stdout
+	var closer io.Closer = nopCloser{}
+	if cfg.Dir != "" {
+		logFile, err := OpenRotatingFile(filepath.Join(cfg.Dir, cfg.FileName), cfg.Rotation)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = io.MultiWriter(stdout, logFile)
+		closer = logFile
+	}
 
-	// Open the log file
-	logFilePath := "logs/" + logFileName
-	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	handler, err := NewHandler(out, cfg.Format, cfg.Level)
 	if err != nil {
-		log.Printf("Error opening log file %s, falling back to stdout only: %v", logFilePath, err)
-		logFile = nil // Continue without a log file
-	} else {
-		log.Printf("Logging initialized. Log file: %s", logFilePath)
+		closer.Close()
+		return nil, nil, err
 	}
+	return slog.New(handler), closer, nil
+}
 
-	// Set up multi-writer to write to stdout and file if possible
-	var multiWriter io.Writer
-	if logFile != nil {
-		multiWriter = io.MultiWriter(os.Stdout, logFile)
-	} else {
-		multiWriter = os.Stdout
+// NewHandler returns a text or JSON handler writing records of at least
+// level to w, including the source location of each call and the request ID
+// and operation of the context, if any.
+func NewHandler(w io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{AddSource: true, Level: level}
+	switch format {
+	case "", FormatText:
+		return NewContextHandler(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return NewContextHandler(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
 	}
+}
 
-	// Configure log format to include timestamp, log level, and file location
-	log.SetOutput(multiWriter)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile)
+// Component returns the attribute naming the part of the program that logs.
+func Component(name string) slog.Attr {
+	return slog.String(ComponentKey, name)
+}
 
-	return logFile, nil
+// Repository returns the attribute naming the repository implementation.
+func Repository(name string) slog.Attr {
+	return slog.String(RepositoryKey, name)
 }
+
+// Operation returns the attribute naming the repository operation.
+func Operation(name string) slog.Attr {
+	return slog.String(OperationKey, name)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,314 @@
+package pkgs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout prefixes rotated files with the time they were rotated.
+// Files written by earlier versions, prefixed with the start time of the run
+// without milliseconds, are recognised as backups too.
+const backupTimeLayout = "20060102_150405.000"
+
+// RotationConfig controls when a RotatingFile starts a new file and which
+// rotated files it keeps. Zero values disable the corresponding rule.
+type RotationConfig struct {
+	// MaxSize rotates the file before a write would make it exceed this many bytes.
+	MaxSize int64
+	// MaxAge rotates the file once it is older than this.
+	MaxAge time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+	// MaxFiles keeps at most this many rotated files, removing the oldest.
+	MaxFiles int
+	// MaxDays removes rotated files older than this many days.
+	MaxDays int
+}
+
+// RotatingFile is an io.WriteCloser appending to a file that is rotated by
+// size and age. Rotated files are renamed to <time>_<name> in the same
+// directory, then compressed and pruned in the background.
+type RotatingFile struct {
+	path          string
+	config        RotationConfig
+	backupPattern *regexp.Regexp
+
+	mu sync.Mutex
+	// file is nil once closed, or when reopening it after a failed
+	// rotation failed too, in which case the next write retries
+	file     *os.File
+	closed   bool
+	size     int64
+	openedAt time.Time
+
+	millSignal chan struct{}
+	millDone   chan struct{}
+}
+
+// OpenRotatingFile opens or creates the file at path for appending. The age
+// of an existing file is taken from its modification time.
+func OpenRotatingFile(path string, config RotationConfig) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	name := filepath.Base(path)
+	r := &RotatingFile{
+		path:          path,
+		config:        config,
+		backupPattern: regexp.MustCompile(`^\d{8}_\d{6}(\.\d{3})?_` + regexp.QuoteMeta(name) + `(\.gz)?$`),
+		millSignal:    make(chan struct{}, 1),
+		millDone:      make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	go r.mill()
+	// Apply the retention policy to the files left by earlier runs
+	r.signalMill()
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	if r.size > 0 {
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends p to the file, rotating it first when p would exceed
+// MaxSize or the file is older than MaxAge. When the rotation fails but the
+// file could be reopened, p is still written and the failure is reported on
+// stderr, so that logging goes on.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.ensureOpen(); err != nil {
+		return 0, err
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			if r.file == nil {
+				return 0, err
+			}
+			fmt.Fprintf(os.Stderr, "pkgs: %v\n", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// ensureOpen reopens the file if a failed rotation left it closed. The
+// caller must hold the lock.
+func (r *RotatingFile) ensureOpen() error {
+	if r.closed {
+		return os.ErrClosed
+	}
+	if r.file == nil {
+		return r.open()
+	}
+	return nil
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.config.MaxSize > 0 && r.size+n > r.config.MaxSize {
+		return true
+	}
+	return r.config.MaxAge > 0 && time.Since(r.openedAt) >= r.config.MaxAge
+}
+
+// Rotate closes the current file, renames it and starts a new one.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureOpen(); err != nil {
+		return err
+	}
+	return r.rotate()
+}
+
+// rotate renames the file and opens a new one. When the rename fails, the
+// file is reopened under its own name to keep appending to it.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	backup := filepath.Join(filepath.Dir(r.path), time.Now().Format(backupTimeLayout)+"_"+filepath.Base(r.path))
+	if err := os.Rename(r.path, backup); err != nil {
+		err = fmt.Errorf("failed to rotate log file: %w", err)
+		return errors.Join(err, r.open())
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.signalMill()
+	return nil
+}
+
+// Close closes the file and waits for pending compression and cleanup.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return os.ErrClosed
+	}
+	r.closed = true
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+
+	close(r.millSignal)
+	<-r.millDone
+	return err
+}
+
+func (r *RotatingFile) signalMill() {
+	select {
+	case r.millSignal <- struct{}{}:
+	default: // a run is already pending
+	}
+}
+
+// mill compresses and prunes rotated files each time it is signalled.
+// Failures only affect old files, so they are reported on stderr rather
+// than failing the write that caused the rotation.
+func (r *RotatingFile) mill() {
+	defer close(r.millDone)
+	for range r.millSignal {
+		if err := r.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "pkgs: failed to clean up rotated logs: %v\n", err)
+		}
+	}
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+func (r *RotatingFile) millOnce() error {
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if r.config.Compress {
+		for i, backup := range backups {
+			if filepath.Ext(backup.path) == ".gz" {
+				continue
+			}
+			if err := compressFile(backup.path); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			backups[i].path += ".gz"
+		}
+	}
+
+	// backups are sorted newest first
+	cutoff := time.Now().AddDate(0, 0, -r.config.MaxDays)
+	for i, backup := range backups {
+		tooMany := r.config.MaxFiles > 0 && i >= r.config.MaxFiles
+		tooOld := r.config.MaxDays > 0 && backup.modTime.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(backup.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups lists the rotated files of r, newest first.
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(r.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		if entry.IsDir() || !r.backupPattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+	return backups, nil
+}
+
+// compressFile replaces path with a gzipped copy at path.gz, keeping its
+// modification time so retention still sees when it was written.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(path+".gz", info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,247 @@
+package pkgs
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeFile creates name in dir with content and sets its modification time.
+func writeFile(t *testing.T, dir, name, content string, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readFile returns the content of path.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// listDir returns the sorted names of the files in dir.
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// backupNames returns the names of the rotated files of test.log in dir.
+func backupNames(t *testing.T, dir string) []string {
+	t.Helper()
+	var backups []string
+	for _, name := range listDir(t, dir) {
+		if name != "test.log" {
+			backups = append(backups, name)
+		}
+	}
+	return backups
+}
+
+func openRotatingFile(t *testing.T, path string, config RotationConfig) *RotatingFile {
+	t.Helper()
+	r, err := OpenRotatingFile(path, config)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	return r
+}
+
+func write(t *testing.T, r *RotatingFile, content string) {
+	t.Helper()
+	if n, err := r.Write([]byte(content)); err != nil || n != len(content) {
+		t.Fatalf("Write(%q) = %d, %v", content, n, err)
+	}
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	r := openRotatingFile(t, path, RotationConfig{MaxSize: 10})
+
+	write(t, r, "first\n")
+	write(t, r, "second\n") // would exceed 10 bytes
+	write(t, r, "x\n")
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := readFile(t, path); got != "second\nx\n" {
+		t.Errorf("log file = %q, want %q", got, "second\nx\n")
+	}
+	backups := backupNames(t, dir)
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want one", backups)
+	}
+	if !r.backupPattern.MatchString(backups[0]) {
+		t.Errorf("backup %q does not match the backup pattern", backups[0])
+	}
+	if got := readFile(t, filepath.Join(dir, backups[0])); got != "first\n" {
+		t.Errorf("backup = %q, want %q", got, "first\n")
+	}
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "test.log", "old\n", time.Now().Add(-2*time.Hour))
+	r := openRotatingFile(t, path, RotationConfig{MaxAge: time.Hour})
+
+	write(t, r, "new\n")
+	write(t, r, "newer\n") // the new file is younger than MaxAge
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if got := readFile(t, path); got != "new\nnewer\n" {
+		t.Errorf("log file = %q, want %q", got, "new\nnewer\n")
+	}
+	backups := backupNames(t, dir)
+	if len(backups) != 1 || readFile(t, filepath.Join(dir, backups[0])) != "old\n" {
+		t.Errorf("backups = %v, want one holding the old file", backups)
+	}
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		config RotationConfig
+		want   []string
+	}{
+		{
+			name:   "max files",
+			config: RotationConfig{MaxFiles: 2},
+			want:   []string{"20240103_120000.000_test.log", "20240104_120000.000_test.log.gz"},
+		},
+		{
+			name:   "max days",
+			config: RotationConfig{MaxDays: 3},
+			want:   []string{"20240103_120000.000_test.log", "20240104_120000.000_test.log.gz"},
+		},
+		{
+			name:   "keep all",
+			config: RotationConfig{},
+			want: []string{
+				"20240101_120000_test.log",
+				"20240102_120000.000_test.log",
+				"20240103_120000.000_test.log",
+				"20240104_120000.000_test.log.gz",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// Backups are ordered by modification time, not by name
+			writeFile(t, dir, "20240101_120000_test.log", "legacy", now.AddDate(0, 0, -10))
+			writeFile(t, dir, "20240102_120000.000_test.log", "older", now.AddDate(0, 0, -5))
+			writeFile(t, dir, "20240103_120000.000_test.log", "newer", now.AddDate(0, 0, -2))
+			writeFile(t, dir, "20240104_120000.000_test.log.gz", "newest", now.AddDate(0, 0, -1))
+			// Files of other logs are left alone
+			writeFile(t, dir, "20240101_120000_other.log", "other", now.AddDate(0, 0, -10))
+			writeFile(t, dir, "notes.txt", "notes", now.AddDate(0, 0, -10))
+
+			r := openRotatingFile(t, filepath.Join(dir, "test.log"), tt.config)
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			want := append(slices.Clone(tt.want), "20240101_120000_other.log", "notes.txt", "test.log")
+			slices.Sort(want)
+			if got := listDir(t, dir); !slices.Equal(got, want) {
+				t.Errorf("files = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileCompressKeepsModTime(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().AddDate(0, 0, -3).Truncate(time.Second)
+	backup := writeFile(t, dir, "20240101_120000.000_test.log", "rotated\n", modTime)
+
+	r := openRotatingFile(t, filepath.Join(dir, "test.log"), RotationConfig{Compress: true})
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := os.Stat(backup); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("uncompressed backup still exists: %v", err)
+	}
+	info, err := os.Stat(backup + ".gz")
+	if err != nil {
+		t.Fatalf("compressed backup missing: %v", err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("compressed backup modified at %v, want %v", info.ModTime(), modTime)
+	}
+
+	f, err := os.Open(backup + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("invalid gzip: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if string(data) != "rotated\n" {
+		t.Errorf("decompressed = %q, want %q", data, "rotated\n")
+	}
+}
+
+func TestRotatingFileRecoversFromFailedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	r := openRotatingFile(t, path, RotationConfig{})
+	write(t, r, "first\n")
+
+	// Renaming a file that is gone fails
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded, want a rename error")
+	}
+
+	write(t, r, "second\n")
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := readFile(t, path); got != "second\n" {
+		t.Errorf("log file = %q, want %q", got, "second\n")
+	}
+	if err := r.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second Close = %v, want %v", err, os.ErrClosed)
+	}
+	if _, err := r.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, os.ErrClosed)
+	}
+}